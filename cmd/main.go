@@ -4,6 +4,7 @@ import (
 	"log"
 	"notification-service/config"
 	"notification-service/internal/routes"
+	_ "time/tzdata" // Embed zone data so cron timezones resolve in minimal images
 )
 
 func main() {
@@ -28,6 +29,13 @@ func main() {
 	engine := serverConfig.Gin
 
 	routes.RegisterRoutes(engine, serverConfig.Controller.NotificationController)
+	routes.RegisterDeviceRoutes(engine, serverConfig.JWTService, serverConfig.Controller.DeviceController)
+	routes.RegisterTemplateRoutes(engine, serverConfig.JWTService, serverConfig.Controller.TemplateController)
+	routes.RegisterSuppressionRoutes(engine, serverConfig.JWTService, serverConfig.Controller.SuppressionController)
+	routes.RegisterTrackingRoutes(engine, serverConfig.JWTService, serverConfig.Controller.TrackingController)
+	routes.RegisterSMSRoutes(engine, serverConfig.Controller.SMSController)
+	routes.RegisterWebhookRoutes(engine, serverConfig.JWTService, serverConfig.Controller.WebhookController)
+	routes.RegisterChatRoutes(engine, serverConfig.JWTService, serverConfig.Controller.ChatController)
 	// Run server
 	log.Println("Starting server on :8083")
 	err = engine.Run(":" + serverConfig.Config.AppPort)
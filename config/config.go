@@ -19,27 +19,70 @@ import (
 
 // Config holds application-wide configurations
 type Config struct {
-	AppPort      string `envconfig:"APP_PORT" default:"8083"`
-	JWTSecret    string `envconfig:"JWT_SECRET" default:"a1b2c3d4e5f6g7h8i9j0k1l2m3n4o5p6q7r8s9t0u1v2w3x4y5z6"`
-	FCMServerKey string `envconfig:"FCM_SERVER_KEY" default:"BIwxUj-dc80uP1e1_2Ka8Mmj_rt7dog6Z_i0AXtC8RoxhdruECcF2GYeAdKLOezF9ujRocVhNf9oA2bnCjJgiIA"`
-	RedisHost    string `envconfig:"REDIS_HOST" default:"localhost"`
-	RedisPort    string `envconfig:"REDIS_PORT" default:"6379"`
-	RedisDB      int    `envconfig:"REDIS_DB" default:"0"`
-	RedisPass    string `envconfig:"REDIS_PASSWORD" default:""`
-	DBHost       string `envconfig:"DB_HOST" default:"localhost"`
-	DBPort       string `envconfig:"DB_PORT" default:"5432"`
-	DBUser       string `envconfig:"DB_USER" default:"postgres"`
-	DBPassword   string `envconfig:"DB_PASSWORD" default:"admin"`
-	DBName       string `envconfig:"DB_NAME" default:"notification"`
-	DBSchema     string `envconfig:"DB_SCHEMA" default:"public"`
-	DBSSLMode    string `envconfig:"DB_SSLMODE" default:"disable"`
-	SMTPHost     string `envconfig:"SMTP_HOST" default:"smtp.gmail.com"`
-	SMTPPort     string `envconfig:"SMTP_PORT" default:"587"`
-	SMTPEmail    string `envconfig:"SMTP_EMAIL" default:""`
-	SMTPPassword string `envconfig:"SMTP_PASSWORD" default:""`
-	NatsUrl      string `envconfig:"NATS_URL" default:"nats://localhost:4222"`
-	FCMFilePath  string `envconfig:"FCM_FILE_PATH" default:"my-home-6b368.json"`
-	FCMProjectID string `envconfig:"FCM_PROJECT_ID" default:"my-home-6b368"`
+	AppPort                string        `envconfig:"APP_PORT" default:"8083"`
+	JWTSecret              string        `envconfig:"JWT_SECRET" default:"a1b2c3d4e5f6g7h8i9j0k1l2m3n4o5p6q7r8s9t0u1v2w3x4y5z6"`
+	FCMServerKey           string        `envconfig:"FCM_SERVER_KEY" default:"BIwxUj-dc80uP1e1_2Ka8Mmj_rt7dog6Z_i0AXtC8RoxhdruECcF2GYeAdKLOezF9ujRocVhNf9oA2bnCjJgiIA"`
+	RedisHost              string        `envconfig:"REDIS_HOST" default:"localhost"`
+	RedisPort              string        `envconfig:"REDIS_PORT" default:"6379"`
+	RedisDB                int           `envconfig:"REDIS_DB" default:"0"`
+	RedisPass              string        `envconfig:"REDIS_PASSWORD" default:""`
+	DBHost                 string        `envconfig:"DB_HOST" default:"localhost"`
+	DBPort                 string        `envconfig:"DB_PORT" default:"5432"`
+	DBUser                 string        `envconfig:"DB_USER" default:"postgres"`
+	DBPassword             string        `envconfig:"DB_PASSWORD" default:"admin"`
+	DBName                 string        `envconfig:"DB_NAME" default:"notification"`
+	DBSchema               string        `envconfig:"DB_SCHEMA" default:"public"`
+	DBSSLMode              string        `envconfig:"DB_SSLMODE" default:"disable"`
+	SMTPHost               string        `envconfig:"SMTP_HOST" default:"smtp.gmail.com"`
+	SMTPPort               string        `envconfig:"SMTP_PORT" default:"587"`
+	SMTPEmail              string        `envconfig:"SMTP_EMAIL" default:""`
+	SMTPPassword           string        `envconfig:"SMTP_PASSWORD" default:""`
+	SMTPFromName           string        `envconfig:"SMTP_FROM_NAME" default:"My Home"`
+	SMTPSecurity           string        `envconfig:"SMTP_SECURITY" default:"starttls"` // starttls, tls (implicit, port 465) or none
+	SMTPAuth               string        `envconfig:"SMTP_AUTH" default:"plain"`        // plain, login, cram-md5, xoauth2 or none
+	SMTPInsecureSkipVerify bool          `envconfig:"SMTP_INSECURE_SKIP_VERIFY" default:"false"`
+	SMTPCAFile             string        `envconfig:"SMTP_CA_FILE" default:""`
+	SMTPPoolSize           int           `envconfig:"SMTP_POOL_SIZE" default:"4"`
+	SMTPDialTimeout        time.Duration `envconfig:"SMTP_DIAL_TIMEOUT" default:"10s"`
+	SMTPTimeout            time.Duration `envconfig:"SMTP_TIMEOUT" default:"30s"`
+	SMTPIdleTimeout        time.Duration `envconfig:"SMTP_IDLE_TIMEOUT" default:"1m"`
+	EmailFrom              string        `envconfig:"EMAIL_FROM" default:""`          // sender address; defaults to SMTP_EMAIL
+	EmailTransport         string        `envconfig:"EMAIL_TRANSPORT" default:"smtp"` // smtp, sendgrid, mailgun or json
+	EmailAPIEndpoint       string        `envconfig:"EMAIL_API_ENDPOINT" default:""`
+	EmailAPIKey            string        `envconfig:"EMAIL_API_KEY" default:""`
+	EmailAPITimeout        time.Duration `envconfig:"EMAIL_API_TIMEOUT" default:"30s"`
+	EmailWebhookSecret     string        `envconfig:"EMAIL_WEBHOOK_SECRET" default:""`    // bounce webhooks are rejected while empty
+	TrackingBaseURL        string        `envconfig:"EMAIL_TRACKING_BASE_URL" default:""` // public URL of this service; tracking is off while empty
+	TrackingSecret         string        `envconfig:"EMAIL_TRACKING_SECRET" default:""`
+	TrackingDedupeWindow   time.Duration `envconfig:"EMAIL_TRACKING_DEDUPE_WINDOW" default:"1h"`
+	DKIMDomain             string        `envconfig:"DKIM_DOMAIN" default:""` // signing is off while empty
+	DKIMSelector           string        `envconfig:"DKIM_SELECTOR" default:"default"`
+	DKIMPrivateKey         string        `envconfig:"DKIM_PRIVATE_KEY" default:""` // PEM; takes precedence over the file
+	DKIMPrivateKeyFile     string        `envconfig:"DKIM_PRIVATE_KEY_FILE" default:""`
+	AttachmentMaxSize      int64         `envconfig:"EMAIL_ATTACHMENT_MAX_SIZE" default:"10485760"`       // bytes per file
+	AttachmentMaxTotalSize int64         `envconfig:"EMAIL_ATTACHMENT_MAX_TOTAL_SIZE" default:"20971520"` // bytes per email
+	SMSProvider            string        `envconfig:"SMS_PROVIDER" default:""`                            // twilio or zenziva; sms sends fail while empty
+	SMSAPIEndpoint         string        `envconfig:"SMS_API_ENDPOINT" default:""`
+	SMSAPIUsername         string        `envconfig:"SMS_API_USERNAME" default:""` // Twilio account SID, Zenziva userkey
+	SMSAPIPassword         string        `envconfig:"SMS_API_PASSWORD" default:""` // Twilio auth token, Zenziva passkey
+	SMSAPITimeout          time.Duration `envconfig:"SMS_API_TIMEOUT" default:"30s"`
+	SMSFrom                string        `envconfig:"SMS_FROM" default:""`
+	SMSStatusCallbackURL   string        `envconfig:"SMS_STATUS_CALLBACK_URL" default:""`
+	SMSWebhookSecret       string        `envconfig:"SMS_WEBHOOK_SECRET" default:""` // delivery receipts are rejected while empty
+	SMSMaxSegments         int           `envconfig:"SMS_MAX_SEGMENTS" default:"6"`
+	WebhookTimeout         time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	TelegramBotToken       string        `envconfig:"TELEGRAM_BOT_TOKEN" default:""`
+	TelegramAPIEndpoint    string        `envconfig:"TELEGRAM_API_ENDPOINT" default:""` // defaults to https://api.telegram.org
+	ChatTimeout            time.Duration `envconfig:"CHAT_TIMEOUT" default:"10s"`
+	ChatMaxWait            time.Duration `envconfig:"CHAT_MAX_WAIT" default:"5s"` // longest a send waits out a rate limit before retrying later
+	NatsUrl                string        `envconfig:"NATS_URL" default:"nats://localhost:4222"`
+	FCMFilePath            string        `envconfig:"FCM_FILE_PATH" default:"my-home-6b368.json"`
+	FCMProjectID           string        `envconfig:"FCM_PROJECT_ID" default:"my-home-6b368"`
+	FCMUserTopics          []string      `envconfig:"FCM_USER_TOPICS" default:"assets"` // topics users may subscribe themselves to
+	CronLockTTL            time.Duration `envconfig:"CRON_LOCK_TTL" default:"30s"`
+	CronSyncInterval       time.Duration `envconfig:"CRON_SYNC_INTERVAL" default:"1m"`
+	RetryInterval          time.Duration `envconfig:"RETRY_INTERVAL" default:"2m"`
+	MaxRetries             int           `envconfig:"MAX_RETRIES" default:"5"`
 }
 
 // LoadConfig loads environment variables into the Config struct
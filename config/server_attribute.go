@@ -1,15 +1,21 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"notification-service/internal/controller"
 	"notification-service/internal/repository"
 	"notification-service/internal/services"
+	"notification-service/internal/templates"
 	"notification-service/internal/utils"
+	"notification-service/internal/utils/chat"
 	controllercron "notification-service/internal/utils/cron/controller"
 	repositorycron "notification-service/internal/utils/cron/repository"
 	"notification-service/internal/utils/cron/service"
+	"notification-service/internal/utils/mail"
 	nt "notification-service/internal/utils/nats"
+	"notification-service/internal/utils/sms"
 	"os"
 	"os/signal"
 	"syscall"
@@ -23,6 +29,14 @@ func NewServerConfig() (*ServerConfig, error) {
 	db := InitDatabase(cfg)
 	engine := InitGin()
 
+	server := &ServerConfig{
+		Gin:        engine,
+		Config:     cfg,
+		DB:         db,
+		Redis:      redisService,
+		JWTService: utils.NewJWTService(cfg.JWTSecret),
+	}
+
 	// Graceful Shutdown Handling
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
@@ -31,21 +45,18 @@ func NewServerConfig() (*ServerConfig, error) {
 		<-quit
 		log.Println("🛑 Shutting down gracefully...")
 
-		// Close database and Redis before exiting
+		// Quit idle SMTP connections, then close database and Redis before exiting
+		if server.SMTPPool != nil {
+			if err := server.SMTPPool.Close(); err != nil {
+				log.Printf("⚠️ Failed to close SMTP connections: %v", err)
+			}
+		}
 		CloseDatabase(db)
 		CloseRedis(redisClient)
 
 		os.Exit(0)
 	}()
 
-	server := &ServerConfig{
-		Gin:        engine,
-		Config:     cfg,
-		DB:         db,
-		Redis:      redisService,
-		JWTService: utils.NewJWTService(cfg.JWTSecret),
-	}
-
 	server.initRepository()
 	server.initServices()
 	server.initController()
@@ -58,21 +69,156 @@ func NewServerConfig() (*ServerConfig, error) {
 func (s *ServerConfig) initRepository() {
 	s.Repository = Repository{
 		NotificationRepository: repository.NewNotificationRepository(*s.DB),
+		DeviceRepository:       repository.NewDeviceRepository(*s.DB),
+		TemplateRepository:     repository.NewTemplateRepository(*s.DB),
+		UserRepository:         repository.NewUserRepository(*s.DB),
+		SuppressionRepository:  repository.NewSuppressionRepository(*s.DB),
+		TrackingRepository:     repository.NewTrackingRepository(*s.DB),
+		WebhookRepository:      repository.NewWebhookRepository(*s.DB),
+		ChatRepository:         repository.NewChatRepository(*s.DB),
 	}
 }
 
 // initServices initializes the application services
 func (s *ServerConfig) initServices() {
+	registry, err := templates.NewRegistry(s.Repository.TemplateRepository)
+	if err != nil {
+		log.Fatalf("❌ Failed to load templates: %v", err)
+	}
+
+	mailer, err := s.initMailTransport()
+	if err != nil {
+		log.Fatalf("❌ Failed to configure email transport: %v", err)
+	}
+
+	from := s.Config.EmailFrom
+	if from == "" {
+		from = s.Config.SMTPEmail
+	}
+
+	smsProvider, err := s.initSMSProvider()
+	if err != nil {
+		log.Fatalf("❌ Failed to configure sms provider: %v", err)
+	}
+
+	chatSender := chat.NewSender(chat.Config{
+		TelegramToken:    s.Config.TelegramBotToken,
+		TelegramEndpoint: s.Config.TelegramAPIEndpoint,
+		Timeout:          s.Config.ChatTimeout,
+		MaxWait:          s.Config.ChatMaxWait,
+	})
+
+	if s.Config.TrackingBaseURL != "" && s.Config.TrackingSecret == "" {
+		log.Fatalf("❌ EMAIL_TRACKING_BASE_URL is set without EMAIL_TRACKING_SECRET")
+	}
+	tracker := services.NewTrackingService(s.Repository.TrackingRepository,
+		s.Repository.NotificationRepository,
+		s.Redis,
+		s.Config.TrackingBaseURL,
+		s.Config.TrackingSecret,
+		s.Config.TrackingDedupeWindow)
+
+	fcm := services.NewFCMClientProvider(s.Config.FCMFilePath, s.Config.FCMProjectID)
 	s.Services = Services{
 		NotificationService: services.NewNotificationService(s.Repository.NotificationRepository,
-			s.Config.FCMFilePath,
-			s.Config.FCMProjectID,
-			s.Config.SMTPHost,
-			s.Config.SMTPPort,
-			s.Config.SMTPEmail,
-			s.Config.SMTPPassword),
+			s.Repository.DeviceRepository,
+			s.Repository.UserRepository,
+			s.Repository.SuppressionRepository,
+			s.Repository.WebhookRepository,
+			s.Repository.ChatRepository,
+			fcm,
+			registry,
+			mailer,
+			tracker,
+			smsProvider,
+			chatSender,
+			from,
+			s.Config.SMTPFromName,
+			services.AttachmentLimits{MaxSize: s.Config.AttachmentMaxSize, MaxTotalSize: s.Config.AttachmentMaxTotalSize},
+			s.Config.SMSMaxSegments,
+			s.Config.WebhookTimeout,
+			s.Config.MaxRetries),
+		DeviceService:      services.NewDeviceService(s.Repository.DeviceRepository, s.Repository.UserRepository, fcm, s.Config.FCMUserTopics),
+		TemplateService:    services.NewTemplateService(s.Repository.TemplateRepository, registry),
+		SuppressionService: services.NewSuppressionService(s.Repository.SuppressionRepository, s.Config.EmailWebhookSecret),
+		TrackingService:    tracker,
+		SMSService:         services.NewSMSService(s.Repository.NotificationRepository, s.Config.SMSWebhookSecret),
+		WebhookService:     services.NewWebhookService(s.Repository.WebhookRepository),
+		ChatService:        services.NewChatService(s.Repository.ChatRepository, chatSender),
+	}
+
+}
+
+// initMailTransport selects SMTP or a provider HTTP API through EMAIL_TRANSPORT
+func (s *ServerConfig) initMailTransport() (mail.Transport, error) {
+	dkim, err := s.initDKIM()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Config.EmailTransport != "smtp" {
+		return mail.NewHTTPTransport(mail.HTTPConfig{
+			Provider: s.Config.EmailTransport,
+			Endpoint: s.Config.EmailAPIEndpoint,
+			APIKey:   s.Config.EmailAPIKey,
+			Timeout:  s.Config.EmailAPITimeout,
+		}, dkim)
 	}
 
+	pool, err := mail.NewSMTPPool(mail.SMTPConfig{
+		Host:               s.Config.SMTPHost,
+		Port:               s.Config.SMTPPort,
+		Username:           s.Config.SMTPEmail,
+		Password:           s.Config.SMTPPassword,
+		Auth:               s.Config.SMTPAuth,
+		Security:           s.Config.SMTPSecurity,
+		InsecureSkipVerify: s.Config.SMTPInsecureSkipVerify,
+		CAFile:             s.Config.SMTPCAFile,
+		PoolSize:           s.Config.SMTPPoolSize,
+		DialTimeout:        s.Config.SMTPDialTimeout,
+		Timeout:            s.Config.SMTPTimeout,
+		IdleTimeout:        s.Config.SMTPIdleTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.SMTPPool = pool
+	return mail.NewSMTPTransport(pool, dkim), nil
+}
+
+// initSMSProvider returns nil when SMS_PROVIDER is unset
+func (s *ServerConfig) initSMSProvider() (sms.Provider, error) {
+	if s.Config.SMSProvider == "" {
+		return nil, nil
+	}
+	return sms.NewProvider(sms.Config{
+		Provider:       s.Config.SMSProvider,
+		Endpoint:       s.Config.SMSAPIEndpoint,
+		Username:       s.Config.SMSAPIUsername,
+		Password:       s.Config.SMSAPIPassword,
+		From:           s.Config.SMSFrom,
+		StatusCallback: s.Config.SMSStatusCallbackURL,
+		Timeout:        s.Config.SMSAPITimeout,
+	})
+}
+
+// initDKIM returns nil when DKIM_DOMAIN is unset, leaving outgoing email unsigned
+func (s *ServerConfig) initDKIM() (*mail.DKIMSigner, error) {
+	if s.Config.DKIMDomain == "" {
+		return nil, nil
+	}
+
+	key := []byte(s.Config.DKIMPrivateKey)
+	if len(key) == 0 {
+		if s.Config.DKIMPrivateKeyFile == "" {
+			return nil, errors.New("DKIM_DOMAIN is set without DKIM_PRIVATE_KEY or DKIM_PRIVATE_KEY_FILE")
+		}
+		var err error
+		if key, err = os.ReadFile(s.Config.DKIMPrivateKeyFile); err != nil {
+			return nil, fmt.Errorf("read dkim private key: %w", err)
+		}
+	}
+	return mail.NewDKIMSigner(s.Config.DKIMDomain, s.Config.DKIMSelector, key)
 }
 
 // Start initializes everything and returns an error if something fails
@@ -84,14 +230,23 @@ func (s *ServerConfig) Start() error {
 func (s *ServerConfig) initController() {
 	s.Controller = Controller{
 		NotificationController: controller.NewNotificationController(s.Services.NotificationService),
+		DeviceController:       controller.NewDeviceController(s.Services.DeviceService),
+		TemplateController:     controller.NewTemplateController(s.Services.TemplateService),
+		SuppressionController:  controller.NewSuppressionController(s.Services.SuppressionService),
+		TrackingController:     controller.NewTrackingController(s.Services.TrackingService),
+		SMSController:          controller.NewSMSController(s.Services.SMSService),
+		WebhookController:      controller.NewWebhookController(s.Services.WebhookService),
+		ChatController:         controller.NewChatController(s.Services.ChatService),
 	}
 }
 
 func (s *ServerConfig) initCron() {
+	cronRepository := repositorycron.NewCronRepository(*s.DB)
+	cronService := service.NewCronService(*s.DB, cronRepository, s.Redis, s.Config.CronLockTTL, s.Config.CronSyncInterval)
 	s.Cron = Cron{
-		CronRepository: repositorycron.NewCronRepository(*s.DB),
-		CronService:    service.NewCronService(*s.DB, repositorycron.NewCronRepository(*s.DB)),
-		CronController: controllercron.NewCronJobController(service.NewCronService(*s.DB, repositorycron.NewCronRepository(*s.DB))),
+		CronRepository: cronRepository,
+		CronService:    cronService,
+		CronController: controllercron.NewCronJobController(cronService),
 	}
 	s.Cron.CronService.Start()
 }
@@ -100,11 +255,14 @@ func (s *ServerConfig) initNats() {
 	s.Nats = Nats{
 		NatsService: nt.NewNatsService(s.Config.NatsUrl, s.Services.NotificationService),
 	}
+	s.Services.NotificationService.SetEventPublisher(s.Nats.NatsService)
 
 	go func() {
 		for {
-			s.Nats.NatsService.RetryPending("notifications.send")
-			time.Sleep(2 * time.Minute)
+			if err := s.Services.NotificationService.RetryPending(); err != nil {
+				log.Printf("⚠️ Retrying pending notifications: %v", err)
+			}
+			time.Sleep(s.Config.RetryInterval)
 		}
 	}()
 
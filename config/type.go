@@ -10,6 +10,7 @@ import (
 	controllercron "notification-service/internal/utils/cron/controller"
 	repositorycron "notification-service/internal/utils/cron/repository"
 	servicescron "notification-service/internal/utils/cron/service"
+	"notification-service/internal/utils/mail"
 	nt "notification-service/internal/utils/nats"
 )
 
@@ -25,20 +26,43 @@ type ServerConfig struct {
 	Repository Repository
 	Cron       Cron
 	Nats       Nats
+	// SMTPPool is nil unless EMAIL_TRANSPORT is smtp
+	SMTPPool *mail.SMTPPool
 }
 
 // Services holds all service dependencies
 type Services struct {
 	NotificationService services.NotificationService
+	DeviceService       services.DeviceService
+	TemplateService     services.TemplateService
+	SuppressionService  services.SuppressionService
+	TrackingService     services.TrackingService
+	SMSService          services.SMSService
+	WebhookService      services.WebhookService
+	ChatService         services.ChatService
 }
 
 // Repository contains repository (database access objects)
 type Repository struct {
 	NotificationRepository repository.NotificationRepository
+	DeviceRepository       repository.DeviceRepository
+	TemplateRepository     repository.TemplateRepository
+	UserRepository         repository.UserRepository
+	SuppressionRepository  repository.SuppressionRepository
+	TrackingRepository     repository.TrackingRepository
+	WebhookRepository      repository.WebhookRepository
+	ChatRepository         repository.ChatRepository
 }
 
 type Controller struct {
 	NotificationController controller.NotificationController
+	DeviceController       controller.DeviceController
+	TemplateController     controller.TemplateController
+	SuppressionController  controller.SuppressionController
+	TrackingController     controller.TrackingController
+	SMSController          controller.SMSController
+	WebhookController      controller.WebhookController
+	ChatController         controller.ChatController
 }
 
 type Cron struct {
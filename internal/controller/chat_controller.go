@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"notification-service/internal/models"
+	"notification-service/internal/services"
+	"notification-service/internal/utils"
+	"notification-service/internal/utils/chat"
+	"notification-service/package/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChatController interface {
+	List(c *gin.Context)
+	Create(c *gin.Context)
+	Get(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	Test(c *gin.Context)
+}
+
+type chatController struct {
+	service services.ChatService
+}
+
+func NewChatController(service services.ChatService) ChatController {
+	return &chatController{service: service}
+}
+
+func (ctrl *chatController) List(c *gin.Context) {
+	channels, err := ctrl.service.GetChannels()
+	if err != nil {
+		sendChatError(c, "Failed to get chat channels", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Chat channels retrieved", channels, nil)
+}
+
+func (ctrl *chatController) Create(c *gin.Context) {
+	var req models.ChatChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	createdBy := ""
+	if claims, ok := utils.ExtractTokenClaims(c); ok {
+		createdBy = claims.ClientID
+	}
+
+	channel, err := ctrl.service.CreateChannel(&req, createdBy)
+	if err != nil {
+		sendChatError(c, "Failed to create chat channel", err)
+		return
+	}
+	response.SendResponse(c, http.StatusCreated, "Chat channel created", channel, nil)
+}
+
+func (ctrl *chatController) Get(c *gin.Context) {
+	id, ok := chatChannelID(c)
+	if !ok {
+		return
+	}
+
+	channel, err := ctrl.service.GetChannel(id)
+	if err != nil {
+		sendChatError(c, "Failed to get chat channel", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Chat channel retrieved", channel, nil)
+}
+
+func (ctrl *chatController) Update(c *gin.Context) {
+	id, ok := chatChannelID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChatChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	channel, err := ctrl.service.UpdateChannel(id, &req)
+	if err != nil {
+		sendChatError(c, "Failed to update chat channel", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Chat channel updated", channel, nil)
+}
+
+func (ctrl *chatController) Delete(c *gin.Context) {
+	id, ok := chatChannelID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.DeleteChannel(id); err != nil {
+		sendChatError(c, "Failed to delete chat channel", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Chat channel deleted", nil, nil)
+}
+
+func (ctrl *chatController) Test(c *gin.Context) {
+	id, ok := chatChannelID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.TestChannel(id); err != nil {
+		sendChatError(c, "Failed to send test message", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Test message sent", nil, nil)
+}
+
+func chatChannelID(c *gin.Context) (uint, bool) {
+	id, err := utils.ConvertToUint(c.Param("id"))
+	if err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid chat channel id", nil, err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+func sendChatError(c *gin.Context, message string, err error) {
+	var rateLimited *chat.RateLimitError
+	switch {
+	case errors.Is(err, services.ErrChatChannelNotFound):
+		response.SendResponse(c, http.StatusNotFound, message, nil, err.Error())
+	case errors.Is(err, services.ErrInvalidChatChannel):
+		response.SendResponse(c, http.StatusBadRequest, message, nil, err.Error())
+	case errors.As(err, &rateLimited):
+		response.SendResponse(c, http.StatusTooManyRequests, message, nil, err.Error())
+	case errors.Is(err, chat.ErrRejected):
+		response.SendResponse(c, http.StatusBadGateway, message, nil, err.Error())
+	default:
+		response.SendResponse(c, http.StatusInternalServerError, message, nil, err.Error())
+	}
+}
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"notification-service/internal/models"
+	"notification-service/internal/services"
+	"notification-service/internal/utils"
+	"notification-service/package/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeviceController interface {
+	Register(c *gin.Context)
+	Unregister(c *gin.Context)
+	List(c *gin.Context)
+	ListTopics(c *gin.Context)
+	SubscribeTopic(c *gin.Context)
+	UnsubscribeTopic(c *gin.Context)
+	SubscribeUserTopic(c *gin.Context)
+	UnsubscribeUserTopic(c *gin.Context)
+}
+
+type deviceController struct {
+	service services.DeviceService
+}
+
+func NewDeviceController(service services.DeviceService) DeviceController {
+	return &deviceController{service: service}
+}
+
+func (ctrl *deviceController) Register(c *gin.Context) {
+	claims, ok := utils.ExtractTokenClaims(c)
+	if !ok {
+		response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, "token claims not found")
+		return
+	}
+
+	var req models.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	device, err := ctrl.service.RegisterDevice(claims.UserID, &req)
+	if err != nil {
+		response.SendResponse(c, http.StatusInternalServerError, "Failed to register device", nil, err.Error())
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Device registered", device, nil)
+}
+
+func (ctrl *deviceController) Unregister(c *gin.Context) {
+	claims, ok := utils.ExtractTokenClaims(c)
+	if !ok {
+		response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, "token claims not found")
+		return
+	}
+
+	var req models.UnregisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	if err := ctrl.service.UnregisterDevice(claims.UserID, req.Token); err != nil {
+		if errors.Is(err, services.ErrDeviceNotFound) {
+			response.SendResponse(c, http.StatusNotFound, "Device not found", nil, err.Error())
+			return
+		}
+		response.SendResponse(c, http.StatusInternalServerError, "Failed to unregister device", nil, err.Error())
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Device unregistered", nil, nil)
+}
+
+func (ctrl *deviceController) List(c *gin.Context) {
+	claims, ok := utils.ExtractTokenClaims(c)
+	if !ok {
+		response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, "token claims not found")
+		return
+	}
+
+	devices, err := ctrl.service.GetActiveDevices(claims.UserID)
+	if err != nil {
+		response.SendResponse(c, http.StatusInternalServerError, "Failed to get devices", nil, err.Error())
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Devices retrieved", devices, nil)
+}
+
+func (ctrl *deviceController) ListTopics(c *gin.Context) {
+	claims, ok := utils.ExtractTokenClaims(c)
+	if !ok {
+		response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, "token claims not found")
+		return
+	}
+
+	topics, err := ctrl.service.GetTopics(claims.UserID)
+	if err != nil {
+		response.SendResponse(c, http.StatusInternalServerError, "Failed to get topics", nil, err.Error())
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Topics retrieved", topics, nil)
+}
+
+func (ctrl *deviceController) SubscribeTopic(c *gin.Context) {
+	claims, ok := utils.ExtractTokenClaims(c)
+	if !ok {
+		response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, "token claims not found")
+		return
+	}
+
+	var req models.TopicSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	if err := ctrl.service.SubscribeTopic(claims.UserID, req.Topic, false); err != nil {
+		sendTopicError(c, "Failed to subscribe to topic", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Subscribed to topic", nil, nil)
+}
+
+func (ctrl *deviceController) UnsubscribeTopic(c *gin.Context) {
+	claims, ok := utils.ExtractTokenClaims(c)
+	if !ok {
+		response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, "token claims not found")
+		return
+	}
+
+	var req models.TopicSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	if err := ctrl.service.UnsubscribeTopic(claims.UserID, req.Topic); err != nil {
+		sendTopicError(c, "Failed to unsubscribe from topic", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Unsubscribed from topic", nil, nil)
+}
+
+// SubscribeUserTopic lets admins subscribe a user's devices to any topic, including those
+// closed to self-subscription
+func (ctrl *deviceController) SubscribeUserTopic(c *gin.Context) {
+	userID, err := utils.ConvertToUint(c.Param("user_id"))
+	if err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid user id", nil, err.Error())
+		return
+	}
+
+	var req models.TopicSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	if err := ctrl.service.SubscribeTopic(userID, req.Topic, true); err != nil {
+		sendTopicError(c, "Failed to subscribe to topic", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Subscribed to topic", nil, nil)
+}
+
+func (ctrl *deviceController) UnsubscribeUserTopic(c *gin.Context) {
+	userID, err := utils.ConvertToUint(c.Param("user_id"))
+	if err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid user id", nil, err.Error())
+		return
+	}
+
+	var req models.TopicSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	if err := ctrl.service.UnsubscribeTopic(userID, req.Topic); err != nil {
+		sendTopicError(c, "Failed to unsubscribe from topic", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Unsubscribed from topic", nil, nil)
+}
+
+func sendTopicError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTopic):
+		response.SendResponse(c, http.StatusBadRequest, message, nil, err.Error())
+	case errors.Is(err, services.ErrTopicNotAllowed):
+		response.SendResponse(c, http.StatusForbidden, message, nil, err.Error())
+	case errors.Is(err, services.ErrNoActiveDevices):
+		response.SendResponse(c, http.StatusNotFound, message, nil, err.Error())
+	default:
+		response.SendResponse(c, http.StatusInternalServerError, message, nil, err.Error())
+	}
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"notification-service/internal/services"
+	"notification-service/package/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SMSController interface {
+	Receipt(c *gin.Context)
+}
+
+type smsController struct {
+	service services.SMSService
+}
+
+func NewSMSController(service services.SMSService) SMSController {
+	return &smsController{service: service}
+}
+
+// Receipt receives delivery receipts; providers authenticate with the shared token in the
+// X-Webhook-Token header or the token query parameter of the callback URL
+func (ctrl *smsController) Receipt(c *gin.Context) {
+	token := webhookToken(c)
+	if err := ctrl.service.AuthorizeWebhook(token); err != nil {
+		response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, err.Error())
+		return
+	}
+	body, ok := readWebhookBody(c)
+	if !ok {
+		return
+	}
+
+	updated, err := ctrl.service.HandleReceipt(c.Param("provider"), token, body)
+	switch {
+	case errors.Is(err, services.ErrWebhookUnauthorized):
+		response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, err.Error())
+	case errors.Is(err, services.ErrUnsupportedProvider):
+		response.SendResponse(c, http.StatusNotFound, "Unknown provider", nil, err.Error())
+	case errors.Is(err, services.ErrInvalidReceipt):
+		response.SendResponse(c, http.StatusBadRequest, "Invalid payload", nil, err.Error())
+	case err != nil:
+		response.SendResponse(c, http.StatusInternalServerError, "Failed to record receipt", nil, err.Error())
+	default:
+		response.SendResponse(c, http.StatusOK, "Receipt recorded", gin.H{"updated": updated}, nil)
+	}
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"notification-service/internal/models"
+	"notification-service/internal/services"
+	"notification-service/package/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SuppressionController interface {
+	Feedback(c *gin.Context)
+	List(c *gin.Context)
+	Get(c *gin.Context)
+	Create(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type suppressionController struct {
+	service services.SuppressionService
+}
+
+func NewSuppressionController(service services.SuppressionService) SuppressionController {
+	return &suppressionController{service: service}
+}
+
+// maxWebhookBody bounds the provider callbacks read before parsing; DSNs that quote the
+// original message are the largest
+const maxWebhookBody = 2 << 20
+
+// Feedback receives bounce and complaint webhooks; providers authenticate with the shared
+// token in the X-Webhook-Token header or the token query parameter
+func (ctrl *suppressionController) Feedback(c *gin.Context) {
+	token := webhookToken(c)
+	if err := ctrl.service.AuthorizeWebhook(token); err != nil {
+		response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, err.Error())
+		return
+	}
+	body, ok := readWebhookBody(c)
+	if !ok {
+		return
+	}
+
+	recorded, err := ctrl.service.HandleFeedback(c.Param("provider"), token, body)
+	switch {
+	case errors.Is(err, services.ErrWebhookUnauthorized):
+		response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, err.Error())
+	case errors.Is(err, services.ErrUnsupportedProvider):
+		response.SendResponse(c, http.StatusNotFound, "Unknown provider", nil, err.Error())
+	case errors.Is(err, services.ErrInvalidFeedbackEvent):
+		response.SendResponse(c, http.StatusBadRequest, "Invalid payload", nil, err.Error())
+	case err != nil:
+		response.SendResponse(c, http.StatusInternalServerError, "Failed to record feedback", nil, err.Error())
+	default:
+		response.SendResponse(c, http.StatusOK, "Feedback recorded", gin.H{"recorded": recorded}, nil)
+	}
+}
+
+func (ctrl *suppressionController) List(c *gin.Context) {
+	suppressions, err := ctrl.service.GetSuppressions(c.Query("email"))
+	if err != nil {
+		response.SendResponse(c, http.StatusInternalServerError, "Failed to get suppressions", nil, err.Error())
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Suppressions retrieved", suppressions, nil)
+}
+
+func (ctrl *suppressionController) Get(c *gin.Context) {
+	detail, err := ctrl.service.GetSuppression(c.Param("email"))
+	if err != nil {
+		sendSuppressionError(c, "Failed to get suppression", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Suppression retrieved", detail, nil)
+}
+
+func (ctrl *suppressionController) Create(c *gin.Context) {
+	var req models.SuppressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	suppression, err := ctrl.service.AddSuppression(&req)
+	if err != nil {
+		sendSuppressionError(c, "Failed to suppress address", err)
+		return
+	}
+	response.SendResponse(c, http.StatusCreated, "Address suppressed", suppression, nil)
+}
+
+func (ctrl *suppressionController) Delete(c *gin.Context) {
+	if err := ctrl.service.RemoveSuppression(c.Param("email")); err != nil {
+		sendSuppressionError(c, "Failed to remove suppression", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Suppression removed", nil, nil)
+}
+
+func sendSuppressionError(c *gin.Context, message string, err error) {
+	if errors.Is(err, services.ErrSuppressionNotFound) {
+		response.SendResponse(c, http.StatusNotFound, message, nil, err.Error())
+		return
+	}
+	response.SendResponse(c, http.StatusInternalServerError, message, nil, err.Error())
+}
+
+// webhookToken reads the shared token from the X-Webhook-Token header or the token query parameter
+func webhookToken(c *gin.Context) string {
+	if token := c.GetHeader("X-Webhook-Token"); token != "" {
+		return token
+	}
+	return c.Query("token")
+}
+
+// readWebhookBody reads at most maxWebhookBody bytes, answering the request itself when it fails
+func readWebhookBody(c *gin.Context) ([]byte, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBody)
+	body, err := c.GetRawData()
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		response.SendResponse(c, http.StatusRequestEntityTooLarge, "Request too large", nil, err.Error())
+		return nil, false
+	case err != nil:
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return nil, false
+	}
+	return body, true
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"notification-service/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedbackRecorder is a SuppressionService accepting the token "secret"
+type feedbackRecorder struct {
+	services.SuppressionService
+	bodies [][]byte
+}
+
+func (s *feedbackRecorder) AuthorizeWebhook(token string) error {
+	if token != "secret" {
+		return services.ErrWebhookUnauthorized
+	}
+	return nil
+}
+
+func (s *feedbackRecorder) HandleFeedback(provider, token string, body []byte) (int, error) {
+	s.bodies = append(s.bodies, body)
+	return 1, nil
+}
+
+// unreadable fails the test when the handler reads the body
+type unreadable struct{ t *testing.T }
+
+func (r unreadable) Read([]byte) (int, error) {
+	r.t.Error("body was read before the token was checked")
+	return 0, io.EOF
+}
+
+func TestFeedbackWebhook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := &feedbackRecorder{}
+	engine := gin.New()
+	engine.POST("/webhooks/email/:provider", NewSuppressionController(service).Feedback)
+
+	tests := []struct {
+		name   string
+		token  string
+		body   io.Reader
+		status int
+	}{
+		{"unauthorized", "wrong", unreadable{t}, http.StatusUnauthorized},
+		{"too large", "secret", bytes.NewReader(make([]byte, maxWebhookBody+1)), http.StatusRequestEntityTooLarge},
+		{"accepted", "secret", bytes.NewReader([]byte(`[]`)), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/email/json?token="+tt.token, tt.body)
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+	if len(service.bodies) != 1 || string(service.bodies[0]) != `[]` {
+		t.Errorf("handled bodies = %q", service.bodies)
+	}
+}
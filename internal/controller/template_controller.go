@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"notification-service/internal/models"
+	"notification-service/internal/services"
+	"notification-service/internal/utils"
+	"notification-service/package/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TemplateController interface {
+	List(c *gin.Context)
+	Create(c *gin.Context)
+	Get(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	ListVersions(c *gin.Context)
+	CreateVersion(c *gin.Context)
+	UpdateVersion(c *gin.Context)
+	PublishVersion(c *gin.Context)
+	Preview(c *gin.Context)
+}
+
+type templateController struct {
+	service services.TemplateService
+}
+
+func NewTemplateController(service services.TemplateService) TemplateController {
+	return &templateController{service: service}
+}
+
+func (ctrl *templateController) List(c *gin.Context) {
+	list, err := ctrl.service.GetTemplates(c.Query("channel"))
+	if err != nil {
+		sendTemplateError(c, "Failed to get templates", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Templates retrieved", list, nil)
+}
+
+func (ctrl *templateController) Create(c *gin.Context) {
+	var req models.TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	template, err := ctrl.service.CreateTemplate(&req)
+	if err != nil {
+		sendTemplateError(c, "Failed to create template", err)
+		return
+	}
+	response.SendResponse(c, http.StatusCreated, "Template created", template, nil)
+}
+
+func (ctrl *templateController) Get(c *gin.Context) {
+	id, ok := templateID(c)
+	if !ok {
+		return
+	}
+
+	template, err := ctrl.service.GetTemplate(id)
+	if err != nil {
+		sendTemplateError(c, "Failed to get template", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Template retrieved", template, nil)
+}
+
+func (ctrl *templateController) Update(c *gin.Context) {
+	id, ok := templateID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	template, err := ctrl.service.UpdateTemplate(id, &req)
+	if err != nil {
+		sendTemplateError(c, "Failed to update template", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Template updated", template, nil)
+}
+
+func (ctrl *templateController) Delete(c *gin.Context) {
+	id, ok := templateID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.DeleteTemplate(id); err != nil {
+		sendTemplateError(c, "Failed to delete template", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Template deleted", nil, nil)
+}
+
+func (ctrl *templateController) ListVersions(c *gin.Context) {
+	id, ok := templateID(c)
+	if !ok {
+		return
+	}
+
+	versions, err := ctrl.service.GetVersions(id)
+	if err != nil {
+		sendTemplateError(c, "Failed to get versions", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Versions retrieved", versions, nil)
+}
+
+func (ctrl *templateController) CreateVersion(c *gin.Context) {
+	id, ok := templateID(c)
+	if !ok {
+		return
+	}
+
+	var req models.TemplateVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	var createdBy string
+	if claims, ok := utils.ExtractTokenClaims(c); ok {
+		createdBy = claims.ClientID
+	}
+
+	version, err := ctrl.service.CreateVersion(id, &req, createdBy)
+	if err != nil {
+		sendTemplateError(c, "Failed to create version", err)
+		return
+	}
+	response.SendResponse(c, http.StatusCreated, "Draft version created", version, nil)
+}
+
+func (ctrl *templateController) UpdateVersion(c *gin.Context) {
+	id, ok := templateID(c)
+	if !ok {
+		return
+	}
+	version, ok := templateVersion(c)
+	if !ok {
+		return
+	}
+
+	var req models.TemplateVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	updated, err := ctrl.service.UpdateVersion(id, version, &req)
+	if err != nil {
+		sendTemplateError(c, "Failed to update version", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Draft version updated", updated, nil)
+}
+
+func (ctrl *templateController) PublishVersion(c *gin.Context) {
+	id, ok := templateID(c)
+	if !ok {
+		return
+	}
+	version, ok := templateVersion(c)
+	if !ok {
+		return
+	}
+
+	published, err := ctrl.service.PublishVersion(id, version)
+	if err != nil {
+		sendTemplateError(c, "Failed to publish version", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Version published", published, nil)
+}
+
+func (ctrl *templateController) Preview(c *gin.Context) {
+	id, ok := templateID(c)
+	if !ok {
+		return
+	}
+
+	var req models.TemplatePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	preview, err := ctrl.service.Preview(id, &req)
+	if err != nil {
+		sendTemplateError(c, "Failed to render preview", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Preview rendered", preview, nil)
+}
+
+func templateID(c *gin.Context) (uint, bool) {
+	id, err := utils.ConvertToUint(c.Param("id"))
+	if err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid template id", nil, err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+func templateVersion(c *gin.Context) (int, bool) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid template version", nil, "version must be a positive number")
+		return 0, false
+	}
+	return version, true
+}
+
+func sendTemplateError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrTemplateNotFound), errors.Is(err, services.ErrVersionNotFound),
+		errors.Is(err, services.ErrTemplateVersionEmpty):
+		response.SendResponse(c, http.StatusNotFound, message, nil, err.Error())
+	case errors.Is(err, services.ErrTemplateExists), errors.Is(err, services.ErrVersionNotDraft),
+		errors.Is(err, services.ErrVersionPublished):
+		response.SendResponse(c, http.StatusConflict, message, nil, err.Error())
+	case errors.Is(err, services.ErrInvalidTemplate):
+		response.SendResponse(c, http.StatusBadRequest, message, nil, err.Error())
+	default:
+		response.SendResponse(c, http.StatusInternalServerError, message, nil, err.Error())
+	}
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"notification-service/internal/services"
+	"notification-service/package/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// transparentGIF is a 1x1 transparent GIF served as the open pixel
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+type TrackingController interface {
+	Open(c *gin.Context)
+	Click(c *gin.Context)
+	Get(c *gin.Context)
+}
+
+type trackingController struct {
+	service services.TrackingService
+}
+
+func NewTrackingController(service services.TrackingService) TrackingController {
+	return &trackingController{service: service}
+}
+
+// Open always serves the pixel, uncached so every open reaches the service
+func (ctrl *trackingController) Open(c *gin.Context) {
+	if err := ctrl.service.RecordOpen(c.Param("token"), c.ClientIP(), c.Request.UserAgent()); err != nil && !errors.Is(err, services.ErrInvalidTrackingToken) {
+		log.Printf("⚠️ Recording open: %v", err)
+	}
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	c.Data(http.StatusOK, "image/gif", transparentGIF)
+}
+
+// Click redirects to the signed link target; a forged or tampered token is never redirected
+func (ctrl *trackingController) Click(c *gin.Context) {
+	target, err := ctrl.service.RecordClick(c.Param("token"), c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		response.SendResponse(c, http.StatusNotFound, "Link not found", nil, err.Error())
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target)
+}
+
+func (ctrl *trackingController) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid notification id", nil, err.Error())
+		return
+	}
+
+	summary, err := ctrl.service.GetTracking(uint(id))
+	if errors.Is(err, services.ErrNotificationNotFound) {
+		response.SendResponse(c, http.StatusNotFound, "Failed to get tracking", nil, err.Error())
+		return
+	}
+	if err != nil {
+		response.SendResponse(c, http.StatusInternalServerError, "Failed to get tracking", nil, err.Error())
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Tracking retrieved", summary, nil)
+}
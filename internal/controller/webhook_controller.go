@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"notification-service/internal/models"
+	"notification-service/internal/services"
+	"notification-service/internal/utils"
+	"notification-service/package/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController interface {
+	List(c *gin.Context)
+	Create(c *gin.Context)
+	Get(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	ListAttempts(c *gin.Context)
+}
+
+type webhookController struct {
+	service services.WebhookService
+}
+
+func NewWebhookController(service services.WebhookService) WebhookController {
+	return &webhookController{service: service}
+}
+
+func (ctrl *webhookController) List(c *gin.Context) {
+	subscriptions, err := ctrl.service.GetSubscriptions()
+	if err != nil {
+		sendWebhookError(c, "Failed to get subscriptions", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Subscriptions retrieved", subscriptions, nil)
+}
+
+func (ctrl *webhookController) Create(c *gin.Context) {
+	var req models.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	createdBy := ""
+	if claims, ok := utils.ExtractTokenClaims(c); ok {
+		createdBy = claims.ClientID
+	}
+
+	subscription, err := ctrl.service.CreateSubscription(&req, createdBy)
+	if err != nil {
+		sendWebhookError(c, "Failed to create subscription", err)
+		return
+	}
+	response.SendResponse(c, http.StatusCreated, "Subscription created", subscription, nil)
+}
+
+func (ctrl *webhookController) Get(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := ctrl.service.GetSubscription(id)
+	if err != nil {
+		sendWebhookError(c, "Failed to get subscription", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Subscription retrieved", subscription, nil)
+}
+
+func (ctrl *webhookController) Update(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	var req models.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid request", nil, err.Error())
+		return
+	}
+
+	subscription, err := ctrl.service.UpdateSubscription(id, &req)
+	if err != nil {
+		sendWebhookError(c, "Failed to update subscription", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Subscription updated", subscription, nil)
+}
+
+func (ctrl *webhookController) Delete(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.service.DeleteSubscription(id); err != nil {
+		sendWebhookError(c, "Failed to delete subscription", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Subscription deleted", nil, nil)
+}
+
+func (ctrl *webhookController) ListAttempts(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+
+	attempts, err := ctrl.service.GetAttempts(id)
+	if err != nil {
+		sendWebhookError(c, "Failed to get attempts", err)
+		return
+	}
+	response.SendResponse(c, http.StatusOK, "Attempts retrieved", attempts, nil)
+}
+
+func subscriptionID(c *gin.Context) (uint, bool) {
+	id, err := utils.ConvertToUint(c.Param("id"))
+	if err != nil {
+		response.SendResponse(c, http.StatusBadRequest, "Invalid subscription id", nil, err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+func sendWebhookError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrSubscriptionNotFound):
+		response.SendResponse(c, http.StatusNotFound, message, nil, err.Error())
+	case errors.Is(err, services.ErrInvalidWebhookURL), errors.Is(err, services.ErrPrivateWebhookURL):
+		response.SendResponse(c, http.StatusBadRequest, message, nil, err.Error())
+	default:
+		response.SendResponse(c, http.StatusInternalServerError, message, nil, err.Error())
+	}
+}
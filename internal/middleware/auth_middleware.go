@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"notification-service/internal/utils"
+	"notification-service/package/response"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthMiddleware validates the bearer token and stores its claims for utils.ExtractTokenClaims
+func AuthMiddleware(jwtService utils.JWTService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		tokenString := strings.TrimPrefix(header, "Bearer ")
+		if header == "" || tokenString == header {
+			response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, "missing bearer token")
+			c.Abort()
+			return
+		}
+
+		claims, err := jwtService.ExtractClaims(tokenString)
+		if err != nil {
+			response.SendResponse(c, http.StatusUnauthorized, "Unauthorized", nil, err.Error())
+			c.Abort()
+			return
+		}
+
+		c.Set(utils.Token, claims)
+		c.Next()
+	}
+}
+
+// AdminMiddleware only lets Admin and Super Admin tokens through
+func AdminMiddleware(jwtService utils.JWTService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if _, err := jwtService.ValidateTokenAdmin(tokenString); err != nil {
+			response.SendResponse(c, http.StatusForbidden, "Forbidden", nil, err.Error())
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
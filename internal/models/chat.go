@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Chat platforms of a ChatChannel
+const (
+	ChatSlack    = "slack"
+	ChatTelegram = "telegram"
+	ChatDiscord  = "discord"
+)
+
+// ChatChannel is a Slack or Discord webhook or a Telegram chat that receives the events matching
+// its patterns, written like webhook subscription filters
+type ChatChannel struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Name       string    `gorm:"not null" json:"name"`
+	Platform   string    `gorm:"not null;index" json:"platform"`         // "slack", "telegram", "discord"
+	WebhookURL string    `gorm:"type:text" json:"webhook_url,omitempty"` // Slack and Discord
+	ChatID     string    `json:"chat_id,omitempty"`                      // Telegram
+	Events     []string  `gorm:"type:text;serializer:json" json:"events"`
+	Active     bool      `gorm:"default:true;index" json:"active"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ChatEvent is what producers publish on the "chat" and "asset" subjects. Without a title or
+// text the published chat template named by Template, or else by EventType, is rendered with
+// Variables, or else with the whole event.
+type ChatEvent struct {
+	EventType string          `json:"event_type"`
+	Template  string          `json:"template"`
+	Variables json.RawMessage `json:"variables"`
+	Locale    string          `json:"locale"`
+	Title     string          `json:"title"`
+	Text      string          `json:"text"`
+	URL       string          `json:"url"`
+	Severity  string          `json:"severity"` // "info", "warning", "critical"
+	Fields    []ChatField     `json:"fields"`
+}
+
+type ChatField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ChatChannelRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	Platform   string   `json:"platform" binding:"required,oneof=slack telegram discord"`
+	WebhookURL string   `json:"webhook_url"`
+	ChatID     string   `json:"chat_id"`
+	Events     []string `json:"events" binding:"required,min=1,dive,required"`
+	Active     *bool    `json:"active"`
+}
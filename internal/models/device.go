@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+type Device struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"not null;index" json:"user_id"`
+	Token         string     `gorm:"not null;uniqueIndex" json:"token"`
+	Platform      string     `gorm:"not null" json:"platform"` // "android", "ios", "web"
+	AppVersion    string     `json:"app_version"`
+	Locale        string     `json:"locale"`
+	IsActive      bool       `gorm:"default:true;index" json:"is_active"`
+	InvalidatedAt *time.Time `json:"invalidated_at,omitempty"` // set when FCM reports the token undeliverable
+	InvalidReason *string    `gorm:"type:text" json:"invalid_reason,omitempty"`
+	LastSeenAt    time.Time  `json:"last_seen_at"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// DeviceTopic records FCM topic membership so it can be listed and undone on unregister
+type DeviceTopic struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	DeviceID  uint      `gorm:"not null;uniqueIndex:idx_device_topics_device_topic" json:"device_id"`
+	Topic     string    `gorm:"not null;uniqueIndex:idx_device_topics_device_topic;index" json:"topic"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type TopicSubscriptionRequest struct {
+	Topic string `json:"topic" binding:"required"`
+}
+
+// DeviceTokenInvalidatedEvent is published so the auth service can clear Users.DeviceToken
+type DeviceTokenInvalidatedEvent struct {
+	UserID        uint      `json:"user_id"`
+	Token         string    `json:"token"`
+	Reason        string    `json:"reason"`
+	InvalidatedAt time.Time `json:"invalidated_at"`
+}
+
+type RegisterDeviceRequest struct {
+	Token      string `json:"token" binding:"required"`
+	Platform   string `json:"platform" binding:"required,oneof=android ios web"`
+	AppVersion string `json:"app_version"`
+	Locale     string `json:"locale"`
+}
+
+type UnregisterDeviceRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -1,8 +1,41 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type Email struct {
-	To       string `json:"to" binding:"required,email"`
-	FullName string `json:"full_name" binding:"required"`
-	URL      string `json:"url" binding:"required"`
-	Subject  string `json:"subject" binding:"required"`
+	To             string            `json:"to" binding:"required,email"`
+	FullName       string            `json:"full_name"`
+	URL            string            `json:"url"`
+	Subject        string            `json:"subject"`    // overrides the template's subject when set
+	EventType      string            `json:"event_type"` // selects the template when Template is empty
+	Template       string            `json:"template"`
+	Variables      json.RawMessage   `json:"variables"`       // template specific, e.g. {"period": "May 2025", "assets": [...]}
+	UserID         uint              `json:"user_id"`         // lets the locale come from the user's devices or profile
+	Locale         string            `json:"locale"`          // e.g. "id-ID"; wins over the device and profile locale
+	UnsubscribeURL string            `json:"unsubscribe_url"` // https or mailto URI sent as List-Unsubscribe
+	Track          *bool             `json:"track"`           // open and click tracking; off by default for transactional templates
+	Attachments    []EmailAttachment `json:"attachments"`
+}
+
+// EmailAttachment carries its file either as base64 Content or as a URL to download it from.
+// Setting ContentID embeds it inline, referenced from the HTML as <img src="cid:logo">.
+type EmailAttachment struct {
+	Filename    string `json:"filename" binding:"required"`
+	ContentType string `json:"content_type"`
+	Content     string `json:"content"`
+	URL         string `json:"url"`
+	ContentID   string `json:"content_id"`
+}
+
+// EmailAttachmentContent keeps the base64 content of a stored email's attachment out of the
+// notification row, which only holds the event without it; Position indexes Email.Attachments
+type EmailAttachmentContent struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	NotificationID uint      `gorm:"not null;index" json:"notification_id"`
+	Position       int       `gorm:"not null" json:"position"`
+	Content        string    `gorm:"type:text" json:"-"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// Email feedback types and bounce classes
+const (
+	FeedbackBounce    = "bounce"
+	FeedbackComplaint = "complaint"
+
+	BounceHard = "hard" // permanent, e.g. 5.1.1 unknown user
+	BounceSoft = "soft" // transient, e.g. 4.2.2 mailbox full
+)
+
+// Suppression reasons
+const (
+	SuppressionHardBounce = "hard_bounce"
+	SuppressionComplaint  = "complaint"
+	SuppressionManual     = "manual"
+)
+
+// EmailFeedback is a bounce or complaint reported for a recipient by a provider webhook or a DSN
+type EmailFeedback struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	Recipient      string    `gorm:"not null;index" json:"recipient"`
+	Type           string    `gorm:"not null;index" json:"type"` // "bounce", "complaint"
+	BounceType     string    `json:"bounce_type,omitempty"`      // "hard", "soft"
+	Status         string    `json:"status,omitempty"`           // enhanced status code, e.g. "5.1.1"
+	Reason         string    `gorm:"type:text" json:"reason,omitempty"`
+	Source         string    `gorm:"not null" json:"source"` // "sendgrid", "mailgun", "json", "dsn"
+	NotificationID *uint     `gorm:"index" json:"notification_id,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// EmailSuppression blocks every future email to the address until it is removed
+type EmailSuppression struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Email     string    `gorm:"not null;uniqueIndex" json:"email"`
+	Reason    string    `gorm:"not null" json:"reason"` // "hard_bounce", "complaint", "manual"
+	Detail    string    `gorm:"type:text" json:"detail,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type SuppressionRequest struct {
+	Email  string `json:"email" binding:"required,email"`
+	Detail string `json:"detail"`
+}
+
+// SuppressionDetail is a suppressed address together with the feedback that led to it
+type SuppressionDetail struct {
+	Suppression *EmailSuppression `json:"suppression"`
+	Feedback    []EmailFeedback   `json:"feedback"`
+}
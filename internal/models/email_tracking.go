@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Email tracking event types
+const (
+	TrackingOpen  = "open"
+	TrackingClick = "click"
+)
+
+// EmailTrackingEvent is an open or click of a tracked email, recorded against its notification row.
+// Repeats inside the dedupe window and requests from bots and link scanners are not recorded.
+type EmailTrackingEvent struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	NotificationID uint      `gorm:"not null;index" json:"notification_id"`
+	Type           string    `gorm:"not null;index" json:"type"`     // "open", "click"
+	URL            string    `gorm:"type:text" json:"url,omitempty"` // click target
+	IP             string    `json:"ip,omitempty"`
+	UserAgent      string    `gorm:"type:text" json:"user_agent,omitempty"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TrackingSummary is the tracking history of one email
+type TrackingSummary struct {
+	NotificationID uint                 `json:"notification_id"`
+	Opens          int                  `json:"opens"`
+	Clicks         int                  `json:"clicks"`
+	Events         []EmailTrackingEvent `json:"events"`
+}
@@ -1,49 +1,133 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
+// Delivery channels of a Notification row
+const (
+	ChannelPush    = "push"
+	ChannelEmail   = "email"
+	ChannelSMS     = "sms"
+	ChannelWebhook = "webhook"
+	ChannelChat    = "chat"
+)
+
 type Notification struct {
-	ID            uint       `gorm:"primaryKey" json:"id"`
-	TargetToken   string     `gorm:"not null;index" json:"target_token"`
-	Title         string     `gorm:"not null" json:"title"`
-	Body          string     `gorm:"not null" json:"body"`
-	Platform      string     `gorm:"not null;index" json:"platform"`        // "android", "web"
-	Priority      string     `gorm:"default:'high'" json:"priority"`        // "high", "normal"
-	Status        string     `gorm:"default:'pending';index" json:"status"` // "pending", "sent", "failed"
-	ServiceSource string     `gorm:"not null;index" json:"service_source"`  // e.g., "auth"
-	EventType     string     `gorm:"not null;index" json:"event_type"`      // e.g., "asset_updated"
-	Payload       string     `gorm:"type:text" json:"payload"`              // raw JSON string
-	Color         string     `gorm:"default:'#000000'" json:"color"`
-	ClickAction   string     `gorm:"default:'OPEN_APP'" json:"click_action"`
-	Icon          string     `gorm:"default:'default'" json:"icon"`
-	Sound         string     `gorm:"default:'default'" json:"sound"`
-	RetryCount    int        `gorm:"default:0" json:"retry_count"`
-	LastError     *string    `gorm:"type:text" json:"last_error,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
-	SentAt        *time.Time `json:"sent_at,omitempty"`
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Channel        string     `gorm:"not null;default:'push';index" json:"channel"`
+	Recipient      string     `gorm:"index" json:"recipient,omitempty"` // email address, phone number, webhook URL or chat channel
+	Subject        string     `json:"subject,omitempty"`                // email
+	TemplateName   string     `json:"template_name,omitempty"`          // template the message was rendered from
+	TargetToken    string     `gorm:"not null;index" json:"target_token"`
+	UserID         *uint      `gorm:"index" json:"user_id,omitempty"`
+	Topic          string     `gorm:"index" json:"topic,omitempty"`         // set instead of TargetToken for topic sends
+	Condition      string     `gorm:"type:text" json:"condition,omitempty"` // e.g. "'assets' in topics && 'admins' in topics"
+	Title          string     `gorm:"not null" json:"title"`
+	Body           string     `gorm:"not null" json:"body"`
+	Platform       string     `gorm:"not null;index" json:"platform"`        // "android", "ios", "web"
+	Priority       string     `gorm:"default:'high'" json:"priority"`        // "high", "normal"
+	Status         string     `gorm:"default:'pending';index" json:"status"` // "pending", "sent", "delivered", "failed", "expired"
+	ServiceSource  string     `gorm:"not null;index" json:"service_source"`  // e.g., "auth"
+	EventType      string     `gorm:"not null;index" json:"event_type"`      // e.g., "asset_updated"
+	Payload        string     `gorm:"type:text" json:"payload"`              // raw JSON string; the whole event for emails
+	Color          string     `gorm:"default:'#000000'" json:"color"`
+	ClickAction    string     `gorm:"default:'OPEN_APP'" json:"click_action"`
+	Icon           string     `gorm:"default:'default'" json:"icon"`
+	Sound          string     `gorm:"default:'default'" json:"sound"`
+	Badge          *int       `json:"badge,omitempty"`                      // APNs badge count
+	Category       string     `json:"category,omitempty"`                   // APNs category
+	ThreadID       string     `json:"thread_id,omitempty"`                  // APNs thread for grouping
+	MutableContent bool       `gorm:"default:false" json:"mutable_content"` // lets the iOS service extension modify the push
+	CollapseKey    string     `json:"collapse_key,omitempty"`               // newer pushes with the same key replace older ones
+	Silent         bool       `gorm:"default:false" json:"silent"`          // data-only push that never shows a banner
+	ImageURL       string     `json:"image_url,omitempty"`
+	ChannelID      string     `json:"channel_id,omitempty"`                 // Android notification channel
+	Actions        string     `gorm:"type:text" json:"actions,omitempty"`   // raw JSON array of NotificationAction
+	DeepLink       string     `gorm:"type:text" json:"deep_link,omitempty"` // raw JSON DeepLink
+	ExpiresAt      *time.Time `gorm:"index" json:"expires_at,omitempty"`    // no delivery or retry after this
+	RetryCount     int        `gorm:"default:0" json:"retry_count"`
+	NextRetryAt    *time.Time `gorm:"index" json:"next_retry_at,omitempty"`
+	LastError      *string    `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`                 // from the provider's delivery receipt
+	ExternalID     string     `gorm:"index" json:"external_id,omitempty"`     // provider message ID that delivery receipts refer to
+	SubscriptionID *uint      `gorm:"index" json:"subscription_id,omitempty"` // webhook subscription the row is delivered to
+	ChatChannelID  *uint      `gorm:"index" json:"chat_channel_id,omitempty"` // chat channel the row is delivered to
 }
 
 type NotificationResponse struct {
-	TargetToken   string            `json:"target_token"`
-	Title         string            `json:"title"`
-	Body          string            `json:"body"`
-	Platform      string            `json:"platform"`
-	ServiceSource string            `json:"service_source"`
-	EventType     string            `json:"event_type"`
-	Payload       map[string]string `json:"payload"`
-	Color         string            `json:"color"`
-	Priority      string            `json:"priority"`
-	ClickAction   string            `json:"click_action"`
+	TargetToken    string               `json:"target_token"`
+	TargetTokens   []string             `json:"target_tokens"`
+	UserID         uint                 `json:"user_id"` // fans out to the user's active devices when TargetToken is empty
+	UserIDs        []uint               `json:"user_ids"`
+	Topic          string               `json:"topic"`
+	Condition      string               `json:"condition"`
+	Title          string               `json:"title"`
+	Body           string               `json:"body"`
+	Platform       string               `json:"platform"`
+	ServiceSource  string               `json:"service_source"`
+	EventType      string               `json:"event_type"`
+	Payload        map[string]string    `json:"payload"`
+	Color          string               `json:"color"`
+	Priority       string               `json:"priority"`
+	ClickAction    string               `json:"click_action"`
+	Icon           string               `json:"icon"`
+	Sound          string               `json:"sound"`
+	Badge          *int                 `json:"badge"`
+	Category       string               `json:"category"`
+	ThreadID       string               `json:"thread_id"`
+	MutableContent bool                 `json:"mutable_content"`
+	TTL            int                  `json:"ttl"` // seconds; zero leaves the FCM default of four weeks
+	CollapseKey    string               `json:"collapse_key"`
+	Silent         bool                 `json:"silent"` // deliver the payload only, without a visible notification
+	ImageURL       string               `json:"image_url"`
+	ChannelID      string               `json:"channel_id"`
+	Actions        []NotificationAction `json:"actions"`
+	DeepLink       *DeepLink            `json:"deep_link"`
+	Template       string               `json:"template"` // published push template that fills Title and Body
+	Variables      json.RawMessage      `json:"variables"`
+	Locale         string               `json:"locale"` // overrides the device and profile locale for Template
 }
 
 type NotificationRequest struct {
-	TargetToken string            `json:"target_token"`
-	Title       string            `json:"title"`
-	Body        string            `json:"body"`
-	Payload     map[string]string `json:"payload"`
-	Color       string            `json:"color"`
-	Priority    string            `json:"priority"`
-	ClickAction string            `json:"click_action"`
+	TargetToken    string               `json:"target_token"`
+	Topic          string               `json:"topic"`
+	Condition      string               `json:"condition"`
+	Title          string               `json:"title"`
+	Body           string               `json:"body"`
+	Payload        map[string]string    `json:"payload"`
+	Color          string               `json:"color"`
+	Priority       string               `json:"priority"`
+	ClickAction    string               `json:"click_action"`
+	Platform       string               `json:"platform"` // selects the platform config; empty sends all of them
+	Icon           string               `json:"icon"`
+	Sound          string               `json:"sound"`
+	Badge          *int                 `json:"badge"`
+	Category       string               `json:"category"`
+	ThreadID       string               `json:"thread_id"`
+	MutableContent bool                 `json:"mutable_content"`
+	CollapseKey    string               `json:"collapse_key"`
+	ExpiresAt      *time.Time           `json:"expires_at"`
+	Silent         bool                 `json:"silent"`
+	ImageURL       string               `json:"image_url"`
+	ChannelID      string               `json:"channel_id"`
+	Actions        []NotificationAction `json:"actions"`
+	DeepLink       *DeepLink            `json:"deep_link"`
+}
+
+// NotificationAction is a button shown with the notification; on iOS the buttons come from the
+// APNs category registered by the app, so Category must name one that declares the same IDs
+type NotificationAction struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Icon  string `json:"icon,omitempty"`
+}
+
+// DeepLink is an in-app destination the client resolves by route name
+type DeepLink struct {
+	Route  string            `json:"route"`
+	Params map[string]string `json:"params,omitempty"`
 }
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SMS is the event producers publish on the "sms" subject. The body is either given or rendered
+// from a published sms template; the number falls back to the profile of UserID.
+type SMS struct {
+	To        string          `json:"to"` // +62..., 62... or 08...
+	UserID    uint            `json:"user_id"`
+	EventType string          `json:"event_type"`
+	Body      string          `json:"body"`
+	Template  string          `json:"template"`
+	Variables json.RawMessage `json:"variables"`
+	Locale    string          `json:"locale"`
+}
+
+// SMSReceipt is a delivery receipt normalized from a provider callback
+type SMSReceipt struct {
+	MessageID  string
+	Status     string // the provider's own status, e.g. "delivered", "undelivered"
+	ErrorCode  string
+	Error      string
+	OccurredAt time.Time
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Template struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"not null;uniqueIndex:idx_templates_channel_name_locale" json:"name"`
+	Channel     string    `gorm:"not null;uniqueIndex:idx_templates_channel_name_locale" json:"channel"` // "email", "push", "sms", "chat"
+	Locale      string    `gorm:"not null;default:'en';uniqueIndex:idx_templates_channel_name_locale" json:"locale"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type TemplateVersion struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	TemplateID  uint       `gorm:"not null;uniqueIndex:idx_template_versions_template_version" json:"template_id"`
+	Version     int        `gorm:"not null;uniqueIndex:idx_template_versions_template_version" json:"version"`
+	Status      string     `gorm:"not null;default:'draft';index" json:"status"` // "draft", "published", "archived"
+	Subject     string     `gorm:"type:text" json:"subject,omitempty"`           // email
+	HTML        string     `gorm:"type:text" json:"html,omitempty"`              // email
+	Text        string     `gorm:"type:text" json:"text,omitempty"`              // email plain-text alternative
+	Title       string     `gorm:"type:text" json:"title,omitempty"`             // push, chat
+	Body        string     `gorm:"type:text" json:"body,omitempty"`              // push, sms, chat
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+type TemplateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Channel     string `json:"channel" binding:"required,oneof=email push sms chat"`
+	Locale      string `json:"locale"` // e.g. "id-ID"; defaults to "en"
+	Description string `json:"description"`
+}
+
+type UpdateTemplateRequest struct {
+	Description string `json:"description"`
+}
+
+type TemplateVersionRequest struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+}
+
+type TemplatePreviewRequest struct {
+	Version   int             `json:"version"` // zero previews the latest version, draft or not
+	Variables json.RawMessage `json:"variables"`
+}
+
+type TemplatePreview struct {
+	Template string `json:"template"`
+	Version  int    `json:"version"`
+	Locale   string `json:"locale"`
+	Subject  string `json:"subject,omitempty"`
+	HTML     string `json:"html,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Body     string `json:"body,omitempty"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// UserPreference keeps per-user settings owned by this service; the profile itself belongs to
+// the auth service
+type UserPreference struct {
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	Locale    string    `json:"locale"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
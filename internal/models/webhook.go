@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscription receives the events matching any of its patterns: an exact event type,
+// a prefix such as "asset_*", or "*" for everything
+type WebhookSubscription struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	URL       string    `gorm:"type:text;not null" json:"url"`
+	Secret    string    `gorm:"not null" json:"-"` // HMAC-SHA256 key, only shown when the subscription is created
+	Events    []string  `gorm:"type:text;serializer:json" json:"events"`
+	Active    bool      `gorm:"default:true;index" json:"active"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// WebhookAttempt is one HTTP call of a delivery, kept for every try including the failed ones
+type WebhookAttempt struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	NotificationID uint      `gorm:"not null;index" json:"notification_id"`
+	SubscriptionID uint      `gorm:"not null;index" json:"subscription_id"`
+	Attempt        int       `gorm:"not null" json:"attempt"`
+	StatusCode     int       `json:"status_code,omitempty"` // zero when no response arrived
+	LatencyMs      int64     `json:"latency_ms"`
+	Response       string    `gorm:"type:text" json:"response,omitempty"` // start of the response body
+	Error          string    `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// WebhookEvent is what producers publish on the "webhook" and "asset" subjects; the whole event
+// becomes the data of the callback
+type WebhookEvent struct {
+	EventType string `json:"event_type"`
+	UserID    uint   `json:"user_id"`
+}
+
+// WebhookPayload is the body posted to subscribers; ID stays the same across retries
+type WebhookPayload struct {
+	ID        uint            `json:"id"`
+	EventType string          `json:"event_type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type WebhookSubscriptionRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1,dive,required"`
+	Active *bool    `json:"active"`
+}
+
+// WebhookSubscriptionCreated carries the signing secret, which is not returned again
+type WebhookSubscriptionCreated struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
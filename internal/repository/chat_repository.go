@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"notification-service/internal/models"
+)
+
+type ChatRepository interface {
+	Save(channel *models.ChatChannel) error
+	Update(channel *models.ChatChannel) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.ChatChannel, error)
+	FindAll() ([]models.ChatChannel, error)
+	FindActive() ([]models.ChatChannel, error)
+}
+
+type chatRepository struct {
+	db gorm.DB
+}
+
+func NewChatRepository(db gorm.DB) ChatRepository {
+	return &chatRepository{db: db}
+}
+
+func (r *chatRepository) Save(channel *models.ChatChannel) error {
+	return r.db.Create(channel).Error
+}
+
+// Update saves every field so a channel can be deactivated
+func (r *chatRepository) Update(channel *models.ChatChannel) error {
+	return r.db.Save(channel).Error
+}
+
+func (r *chatRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ChatChannel{}, id).Error
+}
+
+// FindByID returns nil without an error when the channel does not exist
+func (r *chatRepository) FindByID(id uint) (*models.ChatChannel, error) {
+	var channel models.ChatChannel
+	return notFoundAsNil(&channel, r.db.Where("id = ?", id).First(&channel).Error)
+}
+
+func (r *chatRepository) FindAll() ([]models.ChatChannel, error) {
+	var channels []models.ChatChannel
+	err := r.db.Order("id").Find(&channels).Error
+	return channels, err
+}
+
+func (r *chatRepository) FindActive() ([]models.ChatChannel, error) {
+	var channels []models.ChatChannel
+	err := r.db.Where("active = ?", true).Find(&channels).Error
+	return channels, err
+}
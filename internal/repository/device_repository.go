@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"notification-service/internal/models"
+)
+
+type DeviceRepository interface {
+	Save(device *models.Device) error
+	Update(device *models.Device) error
+	FindByToken(token string) (*models.Device, error)
+	FindByTokens(tokens []string) ([]models.Device, error)
+	FindActiveByUserID(userID uint) ([]models.Device, error)
+	Deactivate(userID uint, token string) (bool, error)
+	AddTopic(deviceID uint, topic string) error
+	RemoveTopic(deviceID uint, topic string) error
+	RemoveTopics(deviceID uint) error
+	FindTopicsByDeviceID(deviceID uint) ([]string, error)
+	FindTopicsByUserID(userID uint) ([]string, error)
+}
+
+type deviceRepository struct {
+	db gorm.DB
+}
+
+func NewDeviceRepository(db gorm.DB) DeviceRepository {
+	return &deviceRepository{db: db}
+}
+
+func (r *deviceRepository) Save(device *models.Device) error {
+	return r.db.Create(device).Error
+}
+
+func (r *deviceRepository) Update(device *models.Device) error {
+	return r.db.Save(device).Error
+}
+
+// FindByToken returns nil without an error when the token has never been registered
+func (r *deviceRepository) FindByToken(token string) (*models.Device, error) {
+	var device models.Device
+	err := r.db.Where("token = ?", token).First(&device).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) FindByTokens(tokens []string) ([]models.Device, error) {
+	var devices []models.Device
+	err := r.db.Where("token IN ?", tokens).Find(&devices).Error
+	return devices, err
+}
+
+func (r *deviceRepository) FindActiveByUserID(userID uint) ([]models.Device, error) {
+	var devices []models.Device
+	err := r.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&devices).Error
+	return devices, err
+}
+
+func (r *deviceRepository) Deactivate(userID uint, token string) (bool, error) {
+	result := r.db.Model(&models.Device{}).
+		Where("user_id = ? AND token = ?", userID, token).
+		Update("is_active", false)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *deviceRepository) AddTopic(deviceID uint, topic string) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.DeviceTopic{DeviceID: deviceID, Topic: topic}).Error
+}
+
+func (r *deviceRepository) RemoveTopic(deviceID uint, topic string) error {
+	return r.db.Where("device_id = ? AND topic = ?", deviceID, topic).Delete(&models.DeviceTopic{}).Error
+}
+
+func (r *deviceRepository) RemoveTopics(deviceID uint) error {
+	return r.db.Where("device_id = ?", deviceID).Delete(&models.DeviceTopic{}).Error
+}
+
+func (r *deviceRepository) FindTopicsByDeviceID(deviceID uint) ([]string, error) {
+	var topics []string
+	err := r.db.Model(&models.DeviceTopic{}).Where("device_id = ?", deviceID).Pluck("topic", &topics).Error
+	return topics, err
+}
+
+func (r *deviceRepository) FindTopicsByUserID(userID uint) ([]string, error) {
+	var topics []string
+	deviceIDs := r.db.Model(&models.Device{}).Select("id").Where("user_id = ? AND is_active = ?", userID, true)
+	err := r.db.Model(&models.DeviceTopic{}).Where("device_id IN (?)", deviceIDs).Distinct().Pluck("topic", &topics).Error
+	return topics, err
+}
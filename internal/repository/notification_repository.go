@@ -2,14 +2,22 @@ package repository
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"notification-service/internal/models"
+	"time"
 )
 
 type NotificationRepository interface {
 	Save(notification *models.Notification) error
 	Update(notification *models.Notification) error
+	FindByID(id uint) (*models.Notification, error)
+	FindByExternalID(channel, externalID string) (*models.Notification, error)
 	MarkAsSent(id uint) error
 	GetPendingNotifications() ([]models.Notification, error)
+	SaveAttachmentContents(contents []models.EmailAttachmentContent) error
+	FindAttachmentContents(notificationID uint) ([]models.EmailAttachmentContent, error)
+	DeleteAttachmentContents(notificationID uint) error
+	ClaimRetryableNotifications(createdBefore, now, leaseUntil time.Time, limit int) ([]models.Notification, error)
 }
 
 type notificationRepository struct {
@@ -28,6 +36,18 @@ func (r *notificationRepository) Update(notification *models.Notification) error
 	return r.db.Model(notification).Updates(notification).Error
 }
 
+// FindByID returns nil without an error when the notification does not exist
+func (r *notificationRepository) FindByID(id uint) (*models.Notification, error) {
+	var notification models.Notification
+	return notFoundAsNil(&notification, r.db.Where("id = ?", id).First(&notification).Error)
+}
+
+// FindByExternalID returns nil without an error when no row carries the provider message ID
+func (r *notificationRepository) FindByExternalID(channel, externalID string) (*models.Notification, error) {
+	var notification models.Notification
+	return notFoundAsNil(&notification, r.db.Where("channel = ? AND external_id = ?", channel, externalID).First(&notification).Error)
+}
+
 func (r *notificationRepository) MarkAsSent(id uint) error {
 	return r.db.Model(&models.Notification{}).Where("id = ?", id).Update("status", "sent").Error
 }
@@ -37,3 +57,37 @@ func (r *notificationRepository) GetPendingNotifications() ([]models.Notificatio
 	err := r.db.Where("status = ?", "pending").Find(&notifications).Error
 	return notifications, err
 }
+
+// ClaimRetryableNotifications returns pending rows whose backoff has elapsed, plus rows that never
+// recorded an attempt and are older than createdBefore. The rows are claimed by pushing their
+// next_retry_at to leaseUntil in the same statement; rows locked by another replica are skipped,
+// so concurrent sweepers never pick up the same row.
+func (r *notificationRepository) ClaimRetryableNotifications(createdBefore, now, leaseUntil time.Time, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	claimable := r.db.Model(&models.Notification{}).
+		Select("id").
+		Where("status = ?", "pending").
+		Where("(next_retry_at IS NULL AND created_at < ?) OR next_retry_at <= ?", createdBefore, now).
+		Order("created_at").
+		Limit(limit).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+	err := r.db.Model(&notifications).
+		Clauses(clause.Returning{}).
+		Where("id IN (?)", claimable).
+		Update("next_retry_at", leaseUntil).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) SaveAttachmentContents(contents []models.EmailAttachmentContent) error {
+	return r.db.Create(&contents).Error
+}
+
+func (r *notificationRepository) FindAttachmentContents(notificationID uint) ([]models.EmailAttachmentContent, error) {
+	var contents []models.EmailAttachmentContent
+	err := r.db.Where("notification_id = ?", notificationID).Order("position").Find(&contents).Error
+	return contents, err
+}
+
+func (r *notificationRepository) DeleteAttachmentContents(notificationID uint) error {
+	return r.db.Where("notification_id = ?", notificationID).Delete(&models.EmailAttachmentContent{}).Error
+}
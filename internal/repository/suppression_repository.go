@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"notification-service/internal/models"
+)
+
+type SuppressionRepository interface {
+	SaveFeedback(feedback *models.EmailFeedback) error
+	FindFeedback(recipient string) ([]models.EmailFeedback, error)
+	Suppress(suppression *models.EmailSuppression) error
+	Unsuppress(email string) (bool, error)
+	FindSuppression(email string) (*models.EmailSuppression, error)
+	FindSuppressions(search string) ([]models.EmailSuppression, error)
+}
+
+type suppressionRepository struct {
+	db gorm.DB
+}
+
+func NewSuppressionRepository(db gorm.DB) SuppressionRepository {
+	return &suppressionRepository{db: db}
+}
+
+func (r *suppressionRepository) SaveFeedback(feedback *models.EmailFeedback) error {
+	return r.db.Create(feedback).Error
+}
+
+func (r *suppressionRepository) FindFeedback(recipient string) ([]models.EmailFeedback, error) {
+	var feedback []models.EmailFeedback
+	err := r.db.Where("recipient = ?", recipient).Order("occurred_at DESC").Find(&feedback).Error
+	return feedback, err
+}
+
+// Suppress keeps the first reason an address was suppressed for
+func (r *suppressionRepository) Suppress(suppression *models.EmailSuppression) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(suppression).Error
+}
+
+func (r *suppressionRepository) Unsuppress(email string) (bool, error) {
+	result := r.db.Where("email = ?", email).Delete(&models.EmailSuppression{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindSuppression returns nil without an error when the address is not suppressed
+func (r *suppressionRepository) FindSuppression(email string) (*models.EmailSuppression, error) {
+	var suppression models.EmailSuppression
+	return notFoundAsNil(&suppression, r.db.Where("email = ?", email).First(&suppression).Error)
+}
+
+func (r *suppressionRepository) FindSuppressions(search string) ([]models.EmailSuppression, error) {
+	var suppressions []models.EmailSuppression
+	query := r.db.Order("created_at DESC")
+	if search != "" {
+		query = query.Where("email LIKE ?", "%"+search+"%")
+	}
+	err := query.Find(&suppressions).Error
+	return suppressions, err
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"notification-service/internal/models"
+	"time"
+)
+
+type TemplateRepository interface {
+	Save(template *models.Template) error
+	Update(template *models.Template) error
+	Delete(id uint) error
+	FindAll(channel string) ([]models.Template, error)
+	FindByID(id uint) (*models.Template, error)
+	FindByName(channel, name, locale string) (*models.Template, error)
+	CreateVersion(version *models.TemplateVersion) error
+	UpdateVersion(version *models.TemplateVersion) error
+	FindVersions(templateID uint) ([]models.TemplateVersion, error)
+	FindVersion(templateID uint, version int) (*models.TemplateVersion, error)
+	FindLatestVersion(templateID uint) (*models.TemplateVersion, error)
+	FindPublishedVersion(templateID uint) (*models.TemplateVersion, error)
+	PublishVersion(templateID uint, version int) error
+}
+
+type templateRepository struct {
+	db gorm.DB
+}
+
+func NewTemplateRepository(db gorm.DB) TemplateRepository {
+	return &templateRepository{db: db}
+}
+
+func (r *templateRepository) Save(template *models.Template) error {
+	return r.db.Create(template).Error
+}
+
+func (r *templateRepository) Update(template *models.Template) error {
+	return r.db.Save(template).Error
+}
+
+// Delete removes the template together with its version history
+func (r *templateRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&models.TemplateVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Template{}, id).Error
+	})
+}
+
+func (r *templateRepository) FindAll(channel string) ([]models.Template, error) {
+	var templates []models.Template
+	query := r.db.Order("name, locale")
+	if channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+	err := query.Find(&templates).Error
+	return templates, err
+}
+
+// FindByID returns nil without an error when the template does not exist
+func (r *templateRepository) FindByID(id uint) (*models.Template, error) {
+	var template models.Template
+	return notFoundAsNil(&template, r.db.Where("id = ?", id).First(&template).Error)
+}
+
+// FindByName returns nil without an error when the template does not exist
+func (r *templateRepository) FindByName(channel, name, locale string) (*models.Template, error) {
+	var template models.Template
+	err := r.db.Where("channel = ? AND name = ? AND locale = ?", channel, name, locale).First(&template).Error
+	return notFoundAsNil(&template, err)
+}
+
+// CreateVersion numbers the version after the template's latest one
+func (r *templateRepository) CreateVersion(version *models.TemplateVersion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		err := tx.Model(&models.TemplateVersion{}).
+			Where("template_id = ?", version.TemplateID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+		version.Version = latest + 1
+		return tx.Create(version).Error
+	})
+}
+
+func (r *templateRepository) UpdateVersion(version *models.TemplateVersion) error {
+	return r.db.Save(version).Error
+}
+
+func (r *templateRepository) FindVersions(templateID uint) ([]models.TemplateVersion, error) {
+	var versions []models.TemplateVersion
+	err := r.db.Where("template_id = ?", templateID).Order("version DESC").Find(&versions).Error
+	return versions, err
+}
+
+// FindVersion returns nil without an error when the version does not exist
+func (r *templateRepository) FindVersion(templateID uint, version int) (*models.TemplateVersion, error) {
+	var v models.TemplateVersion
+	return notFoundAsNil(&v, r.db.Where("template_id = ? AND version = ?", templateID, version).First(&v).Error)
+}
+
+// FindLatestVersion returns nil without an error when the template has no versions
+func (r *templateRepository) FindLatestVersion(templateID uint) (*models.TemplateVersion, error) {
+	var v models.TemplateVersion
+	return notFoundAsNil(&v, r.db.Where("template_id = ?", templateID).Order("version DESC").First(&v).Error)
+}
+
+// FindPublishedVersion returns nil without an error when nothing is published
+func (r *templateRepository) FindPublishedVersion(templateID uint) (*models.TemplateVersion, error) {
+	var v models.TemplateVersion
+	return notFoundAsNil(&v, r.db.Where("template_id = ? AND status = ?", templateID, "published").First(&v).Error)
+}
+
+// PublishVersion archives the currently published version and publishes the given one
+func (r *templateRepository) PublishVersion(templateID uint, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.TemplateVersion{}).
+			Where("template_id = ? AND status = ?", templateID, "published").
+			Update("status", "archived").Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.TemplateVersion{}).
+			Where("template_id = ? AND version = ?", templateID, version).
+			Updates(map[string]interface{}{"status": "published", "published_at": time.Now()}).Error
+	})
+}
+
+func notFoundAsNil[T any](value *T, err error) (*T, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"notification-service/internal/models"
+)
+
+type TrackingRepository interface {
+	SaveEvent(event *models.EmailTrackingEvent) error
+	FindEvents(notificationID uint) ([]models.EmailTrackingEvent, error)
+}
+
+type trackingRepository struct {
+	db gorm.DB
+}
+
+func NewTrackingRepository(db gorm.DB) TrackingRepository {
+	return &trackingRepository{db: db}
+}
+
+func (r *trackingRepository) SaveEvent(event *models.EmailTrackingEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *trackingRepository) FindEvents(notificationID uint) ([]models.EmailTrackingEvent, error) {
+	var events []models.EmailTrackingEvent
+	err := r.db.Where("notification_id = ?", notificationID).Order("created_at").Find(&events).Error
+	return events, err
+}
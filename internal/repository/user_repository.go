@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"notification-service/internal/models"
+)
+
+// UserRepository reads the user profiles shared with the auth service and keeps the preferences
+// this service stores per user
+type UserRepository interface {
+	FindByID(id uint) (*models.Users, error)
+	FindByEmail(email string) (*models.Users, error)
+	FindPreference(userID uint) (*models.UserPreference, error)
+	SavePreference(preference *models.UserPreference) error
+}
+
+type userRepository struct {
+	db gorm.DB
+}
+
+func NewUserRepository(db gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+// FindByID returns nil without an error when the user does not exist
+func (r *userRepository) FindByID(id uint) (*models.Users, error) {
+	var user models.Users
+	return notFoundAsNil(&user, r.db.Where("user_id = ?", id).First(&user).Error)
+}
+
+// FindByEmail returns nil without an error when the user does not exist
+func (r *userRepository) FindByEmail(email string) (*models.Users, error) {
+	var user models.Users
+	return notFoundAsNil(&user, r.db.Where("email = ?", email).First(&user).Error)
+}
+
+// FindPreference returns nil without an error when the user has no stored preferences
+func (r *userRepository) FindPreference(userID uint) (*models.UserPreference, error) {
+	var preference models.UserPreference
+	return notFoundAsNil(&preference, r.db.Where("user_id = ?", userID).First(&preference).Error)
+}
+
+func (r *userRepository) SavePreference(preference *models.UserPreference) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(preference).Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"notification-service/internal/models"
+)
+
+type WebhookRepository interface {
+	Save(subscription *models.WebhookSubscription) error
+	Update(subscription *models.WebhookSubscription) error
+	Delete(id uint) error
+	FindByID(id uint) (*models.WebhookSubscription, error)
+	FindAll() ([]models.WebhookSubscription, error)
+	FindActive() ([]models.WebhookSubscription, error)
+	SaveAttempt(attempt *models.WebhookAttempt) error
+	FindAttempts(subscriptionID uint, limit int) ([]models.WebhookAttempt, error)
+}
+
+type webhookRepository struct {
+	db gorm.DB
+}
+
+func NewWebhookRepository(db gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Save(subscription *models.WebhookSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+// Update saves every field so a subscription can be deactivated
+func (r *webhookRepository) Update(subscription *models.WebhookSubscription) error {
+	return r.db.Save(subscription).Error
+}
+
+func (r *webhookRepository) Delete(id uint) error {
+	return r.db.Delete(&models.WebhookSubscription{}, id).Error
+}
+
+// FindByID returns nil without an error when the subscription does not exist
+func (r *webhookRepository) FindByID(id uint) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	return notFoundAsNil(&subscription, r.db.Where("id = ?", id).First(&subscription).Error)
+}
+
+func (r *webhookRepository) FindAll() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.Order("id").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *webhookRepository) FindActive() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.Where("active = ?", true).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *webhookRepository) SaveAttempt(attempt *models.WebhookAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+// FindAttempts returns the newest attempts first
+func (r *webhookRepository) FindAttempts(subscriptionID uint, limit int) ([]models.WebhookAttempt, error) {
+	var attempts []models.WebhookAttempt
+	err := r.db.Where("subscription_id = ?", subscriptionID).Order("created_at DESC").Limit(limit).Find(&attempts).Error
+	return attempts, err
+}
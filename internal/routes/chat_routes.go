@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"notification-service/internal/controller"
+	"notification-service/internal/middleware"
+	"notification-service/internal/utils"
+)
+
+func RegisterChatRoutes(r *gin.Engine, jwtService utils.JWTService, ctrl controller.ChatController) {
+	channels := r.Group("/chat/channels", middleware.AuthMiddleware(jwtService), middleware.AdminMiddleware(jwtService))
+	{
+		channels.GET("", ctrl.List)
+		channels.POST("", ctrl.Create)
+		channels.GET("/:id", ctrl.Get)
+		channels.PUT("/:id", ctrl.Update)
+		channels.DELETE("/:id", ctrl.Delete)
+		channels.POST("/:id/test", ctrl.Test)
+	}
+}
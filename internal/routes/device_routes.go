@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"notification-service/internal/controller"
+	"notification-service/internal/middleware"
+	"notification-service/internal/utils"
+)
+
+func RegisterDeviceRoutes(r *gin.Engine, jwtService utils.JWTService, ctrl controller.DeviceController) {
+	devices := r.Group("/devices", middleware.AuthMiddleware(jwtService))
+	{
+		devices.GET("", ctrl.List)
+		devices.POST("/register", ctrl.Register)
+		devices.POST("/unregister", ctrl.Unregister)
+		devices.GET("/topics", ctrl.ListTopics)
+		devices.POST("/topics/subscribe", ctrl.SubscribeTopic)
+		devices.POST("/topics/unsubscribe", ctrl.UnsubscribeTopic)
+	}
+
+	userTopics := r.Group("/devices/users/:user_id/topics", middleware.AuthMiddleware(jwtService), middleware.AdminMiddleware(jwtService))
+	{
+		userTopics.POST("/subscribe", ctrl.SubscribeUserTopic)
+		userTopics.POST("/unsubscribe", ctrl.UnsubscribeUserTopic)
+	}
+}
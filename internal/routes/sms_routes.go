@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"notification-service/internal/controller"
+)
+
+func RegisterSMSRoutes(r *gin.Engine, ctrl controller.SMSController) {
+	// Providers post here without a JWT; the controller checks the shared webhook token
+	r.POST("/webhooks/sms/:provider", ctrl.Receipt)
+}
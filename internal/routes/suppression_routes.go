@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"notification-service/internal/controller"
+	"notification-service/internal/middleware"
+	"notification-service/internal/utils"
+)
+
+func RegisterSuppressionRoutes(r *gin.Engine, jwtService utils.JWTService, ctrl controller.SuppressionController) {
+	// Providers post here without a JWT; the controller checks the shared webhook token
+	r.POST("/webhooks/email/:provider", ctrl.Feedback)
+
+	suppressions := r.Group("/suppressions", middleware.AuthMiddleware(jwtService), middleware.AdminMiddleware(jwtService))
+	{
+		suppressions.GET("", ctrl.List)
+		suppressions.POST("", ctrl.Create)
+		suppressions.GET("/:email", ctrl.Get)
+		suppressions.DELETE("/:email", ctrl.Delete)
+	}
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"notification-service/internal/controller"
+	"notification-service/internal/middleware"
+	"notification-service/internal/utils"
+)
+
+func RegisterTemplateRoutes(r *gin.Engine, jwtService utils.JWTService, ctrl controller.TemplateController) {
+	templates := r.Group("/templates", middleware.AuthMiddleware(jwtService), middleware.AdminMiddleware(jwtService))
+	{
+		templates.GET("", ctrl.List)
+		templates.POST("", ctrl.Create)
+		templates.GET("/:id", ctrl.Get)
+		templates.PUT("/:id", ctrl.Update)
+		templates.DELETE("/:id", ctrl.Delete)
+		templates.POST("/:id/preview", ctrl.Preview)
+		templates.GET("/:id/versions", ctrl.ListVersions)
+		templates.POST("/:id/versions", ctrl.CreateVersion)
+		templates.PUT("/:id/versions/:version", ctrl.UpdateVersion)
+		templates.POST("/:id/versions/:version/publish", ctrl.PublishVersion)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"notification-service/internal/controller"
+	"notification-service/internal/middleware"
+	"notification-service/internal/utils"
+)
+
+func RegisterTrackingRoutes(r *gin.Engine, jwtService utils.JWTService, ctrl controller.TrackingController) {
+	// Opened from recipients' mail clients; the signed token is the only credential
+	r.GET("/t/o/:token", ctrl.Open)
+	r.GET("/t/c/:token", ctrl.Click)
+
+	tracking := r.Group("/tracking", middleware.AuthMiddleware(jwtService), middleware.AdminMiddleware(jwtService))
+	{
+		tracking.GET("/notifications/:id", ctrl.Get)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"notification-service/internal/controller"
+	"notification-service/internal/middleware"
+	"notification-service/internal/utils"
+)
+
+func RegisterWebhookRoutes(r *gin.Engine, jwtService utils.JWTService, ctrl controller.WebhookController) {
+	subscriptions := r.Group("/webhooks/subscriptions", middleware.AuthMiddleware(jwtService), middleware.AdminMiddleware(jwtService))
+	{
+		subscriptions.GET("", ctrl.List)
+		subscriptions.POST("", ctrl.Create)
+		subscriptions.GET("/:id", ctrl.Get)
+		subscriptions.PUT("/:id", ctrl.Update)
+		subscriptions.DELETE("/:id", ctrl.Delete)
+		subscriptions.GET("/:id/attempts", ctrl.ListAttempts)
+	}
+}
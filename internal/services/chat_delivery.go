@@ -0,0 +1,151 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"notification-service/internal/models"
+	"notification-service/internal/templates"
+	"notification-service/internal/utils/chat"
+	"time"
+)
+
+// SendNotificationChat renders the event once and stores and sends one row per active chat
+// channel whose filters match it; rate limited and failed sends are retried by the sweeper
+func (s *notificationService) SendNotificationChat(data []byte) error {
+	var event models.ChatEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return fmt.Errorf("unmarshal chat event: %w", err)
+	}
+	if event.EventType == "" {
+		return errors.New("chat event has no event_type")
+	}
+
+	channels, err := s.chats.FindActive()
+	if err != nil {
+		return fmt.Errorf("find chat channels: %w", err)
+	}
+
+	var matched []models.ChatChannel
+	for _, channel := range channels {
+		if matchesEvent(channel.Events, event.EventType) {
+			matched = append(matched, channel)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	message, err := s.chatMessage(event, data)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range matched {
+		channel := &matched[i]
+		channelID := channel.ID
+		notif := &models.Notification{
+			Channel:       models.ChannelChat,
+			Recipient:     channel.Name,
+			ChatChannelID: &channelID,
+			Platform:      channel.Platform,
+			TemplateName:  event.Template,
+			EventType:     event.EventType,
+			Title:         message.Title,
+			Body:          message.Text,
+			Payload:       string(data),
+			Status:        "pending",
+			CreatedAt:     time.Now(),
+		}
+		if err := s.repo.Save(notif); err != nil {
+			errs = append(errs, fmt.Errorf("save notification: %w", err))
+			continue
+		}
+		if err := s.recordResult(notif, s.sendChat(channel, message)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// chatMessage uses the event's own title and text, or renders its chat template. Events without
+// a template of their own fall back to their event type as the title when no template matches.
+func (s *notificationService) chatMessage(event models.ChatEvent, data []byte) (*chat.Message, error) {
+	message := &chat.Message{
+		Title:    event.Title,
+		Text:     event.Text,
+		URL:      event.URL,
+		Severity: event.Severity,
+		Footer:   event.EventType,
+	}
+	for _, field := range event.Fields {
+		message.Fields = append(message.Fields, chat.Field{Name: field.Name, Value: field.Value})
+	}
+	if message.Title != "" || message.Text != "" {
+		return message, nil
+	}
+
+	name := event.Template
+	if name == "" {
+		name = event.EventType
+	}
+	variables := event.Variables
+	if len(variables) == 0 {
+		variables = data
+	}
+
+	rendered, err := s.templates.RenderChat(name, event.Locale, variables)
+	switch {
+	case errors.Is(err, templates.ErrTemplateNotFound) && event.Template == "":
+		message.Title = event.EventType
+	case err != nil:
+		return nil, fmt.Errorf("%w: render chat: %w", errUndeliverable, err)
+	default:
+		message.Title = rendered.Title
+		message.Text = rendered.Body
+	}
+	return message, nil
+}
+
+func (s *notificationService) sendChat(channel *models.ChatChannel, message *chat.Message) error {
+	if s.chat == nil {
+		return fmt.Errorf("%w: no chat sender is configured", errUndeliverable)
+	}
+
+	destination := chat.Destination{Platform: channel.Platform, URL: channel.WebhookURL, ChatID: channel.ChatID}
+	if err := s.chat.Send(destination, message); err != nil {
+		if errors.Is(err, chat.ErrRejected) {
+			return fmt.Errorf("%w: send chat: %w", errUndeliverable, err)
+		}
+		return fmt.Errorf("send chat: %w", err)
+	}
+
+	log.Printf("✅ Chat message %q sent to %s channel %s", message.Title, channel.Platform, channel.Name)
+	return nil
+}
+
+// retryChat re-renders the stored event for the row's channel unless it was removed or deactivated
+func (s *notificationService) retryChat(notif *models.Notification) error {
+	if notif.ChatChannelID == nil {
+		return fmt.Errorf("%w: chat row has no channel", errUndeliverable)
+	}
+	channel, err := s.chats.FindByID(*notif.ChatChannelID)
+	if err != nil {
+		return fmt.Errorf("find chat channel: %w", err)
+	}
+	if channel == nil || !channel.Active {
+		return fmt.Errorf("%w: chat channel %d is gone or inactive", errUndeliverable, *notif.ChatChannelID)
+	}
+
+	var event models.ChatEvent
+	if err := json.Unmarshal([]byte(notif.Payload), &event); err != nil {
+		return fmt.Errorf("%w: unmarshal stored chat event: %w", errUndeliverable, err)
+	}
+	message, err := s.chatMessage(event, []byte(notif.Payload))
+	if err != nil {
+		return err
+	}
+	return s.sendChat(channel, message)
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"notification-service/internal/utils"
+	"notification-service/internal/utils/chat"
+)
+
+var (
+	ErrChatChannelNotFound = errors.New("chat channel not found")
+	ErrInvalidChatChannel  = errors.New("invalid chat channel")
+)
+
+type ChatService interface {
+	CreateChannel(request *models.ChatChannelRequest, createdBy string) (*models.ChatChannel, error)
+	GetChannels() ([]models.ChatChannel, error)
+	GetChannel(id uint) (*models.ChatChannel, error)
+	UpdateChannel(id uint, request *models.ChatChannelRequest) (*models.ChatChannel, error)
+	DeleteChannel(id uint) error
+	TestChannel(id uint) error
+}
+
+type chatService struct {
+	repo   repository.ChatRepository
+	sender chat.Sender
+}
+
+func NewChatService(repo repository.ChatRepository, sender chat.Sender) ChatService {
+	return &chatService{repo: repo, sender: sender}
+}
+
+func (s *chatService) CreateChannel(request *models.ChatChannelRequest, createdBy string) (*models.ChatChannel, error) {
+	if err := validateChatChannel(request); err != nil {
+		return nil, err
+	}
+
+	channel := &models.ChatChannel{
+		Name:       request.Name,
+		Platform:   request.Platform,
+		WebhookURL: request.WebhookURL,
+		ChatID:     request.ChatID,
+		Events:     request.Events,
+		Active:     request.Active == nil || *request.Active,
+		CreatedBy:  createdBy,
+	}
+	if err := s.repo.Save(channel); err != nil {
+		return nil, fmt.Errorf("save chat channel: %w", err)
+	}
+	return channel, nil
+}
+
+func (s *chatService) GetChannels() ([]models.ChatChannel, error) {
+	channels, err := s.repo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("find chat channels: %w", err)
+	}
+	return channels, nil
+}
+
+func (s *chatService) GetChannel(id uint) (*models.ChatChannel, error) {
+	channel, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("find chat channel: %w", err)
+	}
+	if channel == nil {
+		return nil, ErrChatChannelNotFound
+	}
+	return channel, nil
+}
+
+func (s *chatService) UpdateChannel(id uint, request *models.ChatChannelRequest) (*models.ChatChannel, error) {
+	if err := validateChatChannel(request); err != nil {
+		return nil, err
+	}
+
+	channel, err := s.GetChannel(id)
+	if err != nil {
+		return nil, err
+	}
+	channel.Name = request.Name
+	channel.Platform = request.Platform
+	channel.WebhookURL = request.WebhookURL
+	channel.ChatID = request.ChatID
+	channel.Events = request.Events
+	if request.Active != nil {
+		channel.Active = *request.Active
+	}
+	if err := s.repo.Update(channel); err != nil {
+		return nil, fmt.Errorf("update chat channel: %w", err)
+	}
+	return channel, nil
+}
+
+func (s *chatService) DeleteChannel(id uint) error {
+	if _, err := s.GetChannel(id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return fmt.Errorf("delete chat channel: %w", err)
+	}
+	return nil
+}
+
+// TestChannel sends a test message straight away, without storing or retrying it
+func (s *chatService) TestChannel(id uint) error {
+	channel, err := s.GetChannel(id)
+	if err != nil {
+		return err
+	}
+
+	message := &chat.Message{
+		Title:    "Test message",
+		Text:     fmt.Sprintf("Notifications for %s will be posted here.", channel.Name),
+		Severity: chat.SeverityInfo,
+		Footer:   "notification-service",
+	}
+	destination := chat.Destination{Platform: channel.Platform, URL: channel.WebhookURL, ChatID: channel.ChatID}
+	if err := s.sender.Send(destination, message); err != nil {
+		return fmt.Errorf("send test message: %w", err)
+	}
+	return nil
+}
+
+// validateChatChannel checks that Slack and Discord channels have an https webhook URL resolving
+// to public addresses and Telegram channels a chat ID
+func validateChatChannel(request *models.ChatChannelRequest) error {
+	if request.Platform == models.ChatTelegram {
+		if request.ChatID == "" {
+			return fmt.Errorf("%w: telegram channels need a chat_id", ErrInvalidChatChannel)
+		}
+		return nil
+	}
+
+	parsed, err := url.Parse(request.WebhookURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%w: %s channels need an https webhook_url", ErrInvalidChatChannel, request.Platform)
+	}
+
+	// Checked again on every send by the chat client, since DNS can change afterwards
+	ips, err := net.LookupIP(parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidChatChannel, err)
+	}
+	for _, ip := range ips {
+		if !utils.IsPublicIP(ip) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrInvalidChatChannel, parsed.Hostname(), ip)
+		}
+	}
+	return nil
+}
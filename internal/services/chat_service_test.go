@@ -0,0 +1,27 @@
+package services
+
+import (
+	"errors"
+	"notification-service/internal/models"
+	"testing"
+)
+
+func TestValidateChatChannel(t *testing.T) {
+	tests := []struct {
+		request models.ChatChannelRequest
+		want    error
+	}{
+		{models.ChatChannelRequest{Platform: models.ChatSlack, WebhookURL: "https://93.184.216.34/services/T0/B0/x"}, nil},
+		{models.ChatChannelRequest{Platform: models.ChatTelegram, ChatID: "-1001234567890"}, nil},
+		{models.ChatChannelRequest{Platform: models.ChatTelegram}, ErrInvalidChatChannel},
+		{models.ChatChannelRequest{Platform: models.ChatDiscord, WebhookURL: "http://93.184.216.34/api/webhooks/1/x"}, ErrInvalidChatChannel},
+		{models.ChatChannelRequest{Platform: models.ChatSlack, WebhookURL: "https://127.0.0.1/hook"}, ErrInvalidChatChannel},
+		{models.ChatChannelRequest{Platform: models.ChatDiscord, WebhookURL: "https://169.254.169.254/latest"}, ErrInvalidChatChannel},
+		{models.ChatChannelRequest{Platform: models.ChatSlack, WebhookURL: "https://localhost/hook"}, ErrInvalidChatChannel},
+	}
+	for _, tt := range tests {
+		if err := validateChatChannel(&tt.request); !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+			t.Errorf("validateChatChannel(%+v) = %v, want %v", tt.request, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"notification-service/internal/models"
+	"time"
+)
+
+// retryGracePeriod keeps the sweeper away from rows whose first send may still be in flight
+const retryGracePeriod = time.Minute
+
+// retryLease hides claimed rows from other sweepers; rows a crashed replica claimed reappear after it
+const retryLease = 10 * time.Minute
+
+// retryBatchSize bounds how many rows one sweep claims
+const retryBatchSize = 500
+
+// retryAfterError is implemented by send errors of channels that are told when to try again,
+// such as a chat platform's rate limit
+type retryAfterError interface {
+	error
+	RetryDelay() time.Duration
+}
+
+// retryBackoff doubles the wait after every attempt, starting at one minute and capped at one hour
+func retryBackoff(retryCount int) time.Duration {
+	backoff := time.Minute << min(retryCount, 6)
+	return min(backoff, time.Hour)
+}
+
+// recordResult updates a notification row of any channel with the outcome of its send.
+// Undeliverable rows fail, others are retried when the channel asks or after the usual backoff.
+func (s *notificationService) recordResult(notif *models.Notification, sendErr error) error {
+	if sendErr != nil {
+		var retryAfter retryAfterError
+		errMsg := sendErr.Error()
+		notif.LastError = &errMsg
+		switch {
+		case errors.Is(sendErr, errUndeliverable):
+			notif.Status = "failed"
+		case errors.As(sendErr, &retryAfter):
+			nextRetryAt := time.Now().Add(retryAfter.RetryDelay())
+			notif.NextRetryAt = &nextRetryAt
+		default:
+			nextRetryAt := time.Now().Add(retryBackoff(notif.RetryCount))
+			notif.NextRetryAt = &nextRetryAt
+		}
+		if err := s.repo.Update(notif); err != nil {
+			log.Printf("⚠️ Failed to record send error for notification %d: %v", notif.ID, err)
+		}
+		return fmt.Errorf("send notification: %w", sendErr)
+	}
+
+	now := time.Now()
+	notif.Status = "sent"
+	notif.SentAt = &now
+	if err := s.repo.Update(notif); err != nil {
+		return fmt.Errorf("update notification: %w", err)
+	}
+	return nil
+}
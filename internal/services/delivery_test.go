@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"notification-service/internal/utils/chat"
+	"testing"
+	"time"
+)
+
+// updateRecorder is a NotificationRepository that only accepts Update
+type updateRecorder struct {
+	repository.NotificationRepository
+	updated []models.Notification
+}
+
+func (r *updateRecorder) Update(notification *models.Notification) error {
+	r.updated = append(r.updated, *notification)
+	return nil
+}
+
+func TestRecordResultSchedulesRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		sendErr error
+		wait    time.Duration
+	}{
+		{"rate limited", fmt.Errorf("send chat: %w", &chat.RateLimitError{Platform: chat.PlatformSlack, RetryAfter: 90 * time.Second}), 90 * time.Second},
+		{"rate limited briefly", &chat.RateLimitError{Platform: chat.PlatformDiscord, RetryAfter: 3 * time.Second}, 3 * time.Second},
+		{"other failure", errors.New("connection reset"), retryBackoff(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &updateRecorder{}
+			s := &notificationService{repo: repo}
+			notif := &models.Notification{ID: 1, Channel: models.ChannelChat, Status: "pending"}
+
+			before := time.Now()
+			if err := s.recordResult(notif, tt.sendErr); err == nil {
+				t.Fatal("recordResult hid the send error")
+			}
+			if len(repo.updated) != 1 || repo.updated[0].Status != "pending" || repo.updated[0].NextRetryAt == nil {
+				t.Fatalf("updated = %+v", repo.updated)
+			}
+			wait := repo.updated[0].NextRetryAt.Sub(before)
+			if wait < tt.wait || wait > tt.wait+time.Second {
+				t.Errorf("next retry in %s, want %s", wait, tt.wait)
+			}
+		})
+	}
+}
+
+func TestRecordResultFailsUndeliverable(t *testing.T) {
+	repo := &updateRecorder{}
+	s := &notificationService{repo: repo}
+	notif := &models.Notification{ID: 1, Channel: models.ChannelChat, Status: "pending"}
+
+	s.recordResult(notif, fmt.Errorf("%w: send chat: %w", errUndeliverable, chat.ErrRejected))
+	if len(repo.updated) != 1 || repo.updated[0].Status != "failed" || repo.updated[0].NextRetryAt != nil {
+		t.Errorf("updated = %+v", repo.updated)
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrDeviceNotFound  = errors.New("device not found")
+	ErrNoActiveDevices = errors.New("no active devices")
+	ErrInvalidTopic    = errors.New("topic may only contain letters, digits and -_.~%")
+	ErrTopicNotAllowed = errors.New("topic is not open to user subscriptions")
+)
+
+// validTopic mirrors the characters FCM accepts in topic names
+var validTopic = regexp.MustCompile(`^[a-zA-Z0-9\-_.~%]{1,900}$`)
+
+type DeviceService interface {
+	RegisterDevice(userID uint, request *models.RegisterDeviceRequest) (*models.Device, error)
+	UnregisterDevice(userID uint, token string) error
+	GetActiveDevices(userID uint) ([]models.Device, error)
+	SubscribeTopic(userID uint, topic string, privileged bool) error
+	UnsubscribeTopic(userID uint, topic string) error
+	GetTopics(userID uint) ([]string, error)
+}
+
+type deviceService struct {
+	repo       repository.DeviceRepository
+	userRepo   repository.UserRepository
+	fcm        FCMClientProvider
+	userTopics map[string]bool
+}
+
+// NewDeviceService takes the topics users may subscribe themselves to; every other topic
+// can only be subscribed by an admin
+func NewDeviceService(repo repository.DeviceRepository, userRepo repository.UserRepository, fcm FCMClientProvider, userTopics []string) DeviceService {
+	allowed := make(map[string]bool, len(userTopics))
+	for _, topic := range userTopics {
+		allowed[topic] = true
+	}
+	return &deviceService{repo: repo, userRepo: userRepo, fcm: fcm, userTopics: allowed}
+}
+
+// RegisterDevice upserts by token, so a token handed to a different user after a re-login moves with them.
+// The device's locale is also kept as the user's locale for messages that reach no device.
+func (s *deviceService) RegisterDevice(userID uint, request *models.RegisterDeviceRequest) (*models.Device, error) {
+	if request.Locale != "" {
+		if err := s.userRepo.SavePreference(&models.UserPreference{UserID: userID, Locale: request.Locale}); err != nil {
+			log.Printf("⚠️ Failed to save locale of user %d: %v", userID, err)
+		}
+	}
+
+	device, err := s.repo.FindByToken(request.Token)
+	if err != nil {
+		return nil, fmt.Errorf("find device: %w", err)
+	}
+
+	now := time.Now()
+	if device == nil {
+		device = &models.Device{
+			UserID:     userID,
+			Token:      request.Token,
+			Platform:   request.Platform,
+			AppVersion: request.AppVersion,
+			Locale:     request.Locale,
+			IsActive:   true,
+			LastSeenAt: now,
+		}
+		if err := s.repo.Save(device); err != nil {
+			return nil, fmt.Errorf("save device: %w", err)
+		}
+		return device, nil
+	}
+
+	// Topic memberships belong to the previous owner and must not follow the token
+	if device.UserID != userID {
+		if err := s.dropTopics(device); err != nil {
+			return nil, err
+		}
+	}
+
+	device.UserID = userID
+	device.Platform = request.Platform
+	device.AppVersion = request.AppVersion
+	device.Locale = request.Locale
+	device.IsActive = true
+	device.InvalidatedAt = nil
+	device.InvalidReason = nil
+	device.LastSeenAt = now
+	if err := s.repo.Update(device); err != nil {
+		return nil, fmt.Errorf("update device: %w", err)
+	}
+	return device, nil
+}
+
+// UnregisterDevice deactivates the device and drops its FCM topic memberships
+func (s *deviceService) UnregisterDevice(userID uint, token string) error {
+	device, err := s.repo.FindByToken(token)
+	if err != nil {
+		return fmt.Errorf("find device: %w", err)
+	}
+	if device == nil || device.UserID != userID {
+		return ErrDeviceNotFound
+	}
+
+	if _, err := s.repo.Deactivate(userID, token); err != nil {
+		return fmt.Errorf("deactivate device: %w", err)
+	}
+
+	topics, err := s.repo.FindTopicsByDeviceID(device.ID)
+	if err != nil {
+		return fmt.Errorf("find device topics: %w", err)
+	}
+	for _, topic := range topics {
+		if err := s.unsubscribeTokens([]models.Device{*device}, topic); err != nil {
+			log.Printf("⚠️ Failed to unsubscribe device %d from %s: %v", device.ID, topic, err)
+		}
+	}
+	return nil
+}
+
+func (s *deviceService) GetActiveDevices(userID uint) ([]models.Device, error) {
+	devices, err := s.repo.FindActiveByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("find devices: %w", err)
+	}
+	return devices, nil
+}
+
+// SubscribeTopic subscribes every active device of the user to an FCM topic. Only allow-listed
+// topics are open to users; privileged callers may use any topic.
+func (s *deviceService) SubscribeTopic(userID uint, topic string, privileged bool) error {
+	if !privileged && !s.userTopics[topic] {
+		return ErrTopicNotAllowed
+	}
+
+	devices, err := s.topicDevices(userID, topic)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := s.fcm.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.SubscribeToTopic(ctx, deviceTokens(devices), topic)
+	if err != nil {
+		return fmt.Errorf("FCM subscribe: %w", err)
+	}
+
+	failed := make(map[int]bool, len(resp.Errors))
+	for _, e := range resp.Errors {
+		failed[e.Index] = true
+		log.Printf("⚠️ Failed to subscribe device %d to %s: %s", devices[e.Index].ID, topic, e.Reason)
+	}
+	for i, device := range devices {
+		if failed[i] {
+			continue
+		}
+		if err := s.repo.AddTopic(device.ID, topic); err != nil {
+			return fmt.Errorf("save device topic: %w", err)
+		}
+	}
+
+	if resp.SuccessCount == 0 {
+		return fmt.Errorf("FCM subscribe: all %d devices failed", resp.FailureCount)
+	}
+	return nil
+}
+
+// UnsubscribeTopic removes every active device of the user from an FCM topic
+func (s *deviceService) UnsubscribeTopic(userID uint, topic string) error {
+	devices, err := s.topicDevices(userID, topic)
+	if err != nil {
+		return err
+	}
+	return s.unsubscribeTokens(devices, topic)
+}
+
+func (s *deviceService) GetTopics(userID uint) ([]string, error) {
+	topics, err := s.repo.FindTopicsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("find topics: %w", err)
+	}
+	return topics, nil
+}
+
+func (s *deviceService) topicDevices(userID uint, topic string) ([]models.Device, error) {
+	if !validTopic.MatchString(topic) {
+		return nil, ErrInvalidTopic
+	}
+
+	devices, err := s.repo.FindActiveByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("find devices: %w", err)
+	}
+	if len(devices) == 0 {
+		return nil, ErrNoActiveDevices
+	}
+	return devices, nil
+}
+
+// dropTopics unsubscribes the device from all of its topics and forgets them
+func (s *deviceService) dropTopics(device *models.Device) error {
+	topics, err := s.repo.FindTopicsByDeviceID(device.ID)
+	if err != nil {
+		return fmt.Errorf("find device topics: %w", err)
+	}
+	for _, topic := range topics {
+		if err := s.unsubscribeTokens([]models.Device{*device}, topic); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.RemoveTopics(device.ID); err != nil {
+		return fmt.Errorf("remove device topics: %w", err)
+	}
+	return nil
+}
+
+func (s *deviceService) unsubscribeTokens(devices []models.Device, topic string) error {
+	ctx := context.Background()
+	client, err := s.fcm.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.UnsubscribeFromTopic(ctx, deviceTokens(devices), topic)
+	if err != nil {
+		return fmt.Errorf("FCM unsubscribe: %w", err)
+	}
+
+	failed := make(map[int]bool, len(resp.Errors))
+	for _, e := range resp.Errors {
+		failed[e.Index] = true
+		log.Printf("⚠️ Failed to unsubscribe device %d from %s: %s", devices[e.Index].ID, topic, e.Reason)
+	}
+	for i, device := range devices {
+		if failed[i] {
+			continue
+		}
+		if err := s.repo.RemoveTopic(device.ID, topic); err != nil {
+			return fmt.Errorf("remove device topic: %w", err)
+		}
+	}
+	return nil
+}
+
+func deviceTokens(devices []models.Device) []string {
+	tokens := make([]string, len(devices))
+	for i, device := range devices {
+		tokens[i] = device.Token
+	}
+	return tokens
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+	"notification-service/internal/utils/mail"
+	"strings"
+	"time"
+)
+
+// ErrAttachmentTooLarge is returned when a file or the email as a whole exceeds AttachmentLimits
+var ErrAttachmentTooLarge = errors.New("attachment too large")
+
+// AttachmentLimits caps attachment sizes in bytes; downloads stop as soon as a limit is crossed
+type AttachmentLimits struct {
+	MaxSize      int64
+	MaxTotalSize int64
+}
+
+// attachmentClient refuses internal addresses, since attachment URLs come from event payloads
+var attachmentClient = utils.NewPublicHTTPClient(30 * time.Second)
+
+// loadAttachments decodes or downloads every attachment of the event, enforcing the size limits
+func (s *notificationService) loadAttachments(attachments []models.EmailAttachment) ([]mail.Attachment, error) {
+	var loaded []mail.Attachment
+	var total int64
+	for _, attachment := range attachments {
+		if attachment.Filename == "" {
+			return nil, errors.New("attachment has no filename")
+		}
+
+		limit := min(s.attachmentLimits.MaxSize, s.attachmentLimits.MaxTotalSize-total)
+		var data []byte
+		var err error
+		switch {
+		case attachment.Content != "":
+			if base64DecodedSize(attachment.Content) > limit {
+				return nil, fmt.Errorf("%w: %s", ErrAttachmentTooLarge, attachment.Filename)
+			}
+			data, err = base64.StdEncoding.DecodeString(attachment.Content)
+			if err != nil {
+				return nil, fmt.Errorf("decode attachment %s: %w", attachment.Filename, err)
+			}
+			if int64(len(data)) > limit {
+				return nil, fmt.Errorf("%w: %s", ErrAttachmentTooLarge, attachment.Filename)
+			}
+		case attachment.URL != "":
+			data, err = downloadAttachment(attachment.URL, limit)
+			if err != nil {
+				return nil, fmt.Errorf("download attachment %s: %w", attachment.Filename, err)
+			}
+		default:
+			return nil, fmt.Errorf("attachment %s has neither content nor url", attachment.Filename)
+		}
+
+		total += int64(len(data))
+		loaded = append(loaded, mail.Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Data:        data,
+			ContentID:   attachment.ContentID,
+		})
+	}
+	return loaded, nil
+}
+
+func downloadAttachment(rawURL string, limit int64) ([]byte, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("unsupported attachment url: %s", rawURL)
+	}
+
+	resp, err := attachmentClient.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if resp.ContentLength > limit {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	// Read one byte past the limit to tell a file of exactly the limit from a larger one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrAttachmentTooLarge
+	}
+	return data, nil
+}
+
+// base64DecodedSize is the size content decodes to, known before decoding it; padding does not count
+func base64DecodedSize(content string) int64 {
+	padding := strings.Count(content[max(len(content)-2, 0):], "=")
+	return int64(base64.StdEncoding.DecodedLen(len(content)) - padding)
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"notification-service/internal/models"
+	"notification-service/internal/utils/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Feedback sources accepted by HandleFeedback
+const (
+	FeedbackSendGrid = "sendgrid"
+	FeedbackMailgun  = "mailgun"
+	FeedbackJSON     = "json"
+	FeedbackDSN      = "dsn" // raw multipart/report message, e.g. piped from the bounce mailbox
+)
+
+// parseFeedback normalizes a provider payload into feedback rows; deliveries, opens and other
+// events the providers also post are skipped
+func parseFeedback(provider string, body []byte) ([]models.EmailFeedback, error) {
+	var feedback []models.EmailFeedback
+	var err error
+	switch provider {
+	case FeedbackSendGrid:
+		feedback, err = parseSendGridFeedback(body)
+	case FeedbackMailgun:
+		feedback, err = parseMailgunFeedback(body)
+	case FeedbackJSON:
+		feedback, err = parseJSONFeedback(body)
+	case FeedbackDSN:
+		feedback, err = parseDSNFeedback(body)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProvider, provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFeedbackEvent, err)
+	}
+
+	for i := range feedback {
+		feedback[i].Source = provider
+		if feedback[i].OccurredAt.IsZero() {
+			feedback[i].OccurredAt = time.Now()
+		}
+	}
+	return feedback, nil
+}
+
+type sendGridEvent struct {
+	Email          string `json:"email"`
+	Event          string `json:"event"`
+	Type           string `json:"type"` // "bounce" or "blocked" for bounce events
+	Reason         string `json:"reason"`
+	Status         string `json:"status"`
+	Timestamp      int64  `json:"timestamp"`
+	NotificationID string `json:"notification_id"` // custom arg
+}
+
+func parseSendGridFeedback(body []byte) ([]models.EmailFeedback, error) {
+	var events []sendGridEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, err
+	}
+
+	var feedback []models.EmailFeedback
+	for _, event := range events {
+		item := models.EmailFeedback{
+			Recipient:      event.Email,
+			Status:         event.Status,
+			Reason:         event.Reason,
+			NotificationID: parseNotificationID(event.NotificationID),
+		}
+		if event.Timestamp > 0 {
+			item.OccurredAt = time.Unix(event.Timestamp, 0)
+		}
+
+		switch event.Event {
+		case "bounce":
+			item.Type = models.FeedbackBounce
+			item.BounceType = models.BounceHard
+			if event.Type == "blocked" || strings.HasPrefix(event.Status, "4") {
+				item.BounceType = models.BounceSoft
+			}
+		case "deferred":
+			item.Type = models.FeedbackBounce
+			item.BounceType = models.BounceSoft
+		case "spamreport":
+			item.Type = models.FeedbackComplaint
+		default:
+			continue
+		}
+		feedback = append(feedback, item)
+	}
+	return feedback, nil
+}
+
+type mailgunEvent struct {
+	EventData struct {
+		Event          string  `json:"event"`
+		Severity       string  `json:"severity"` // "permanent" or "temporary" for failures
+		Recipient      string  `json:"recipient"`
+		Timestamp      float64 `json:"timestamp"`
+		Reason         string  `json:"reason"`
+		DeliveryStatus struct {
+			Code         int    `json:"code"`
+			EnhancedCode string `json:"enhanced-code"`
+			Message      string `json:"message"`
+			Description  string `json:"description"`
+		} `json:"delivery-status"`
+		UserVariables map[string]interface{} `json:"user-variables"`
+	} `json:"event-data"`
+}
+
+func parseMailgunFeedback(body []byte) ([]models.EmailFeedback, error) {
+	var event mailgunEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+	data := event.EventData
+
+	item := models.EmailFeedback{
+		Recipient: data.Recipient,
+		Status:    data.DeliveryStatus.EnhancedCode,
+		Reason:    strings.TrimSpace(data.DeliveryStatus.Message + " " + data.DeliveryStatus.Description),
+	}
+	if data.Timestamp > 0 {
+		item.OccurredAt = time.Unix(int64(data.Timestamp), 0)
+	}
+	if id, ok := data.UserVariables["notification_id"]; ok {
+		item.NotificationID = parseNotificationID(fmt.Sprint(id))
+	}
+
+	switch data.Event {
+	case "failed":
+		item.Type = models.FeedbackBounce
+		item.BounceType = models.BounceHard
+		if data.Severity == "temporary" {
+			item.BounceType = models.BounceSoft
+		}
+	case "complained":
+		item.Type = models.FeedbackComplaint
+	default:
+		return nil, nil
+	}
+	return []models.EmailFeedback{item}, nil
+}
+
+type jsonFeedback struct {
+	Type           string    `json:"type"`
+	Recipient      string    `json:"recipient"`
+	BounceType     string    `json:"bounce_type"`
+	Status         string    `json:"status"`
+	Reason         string    `json:"reason"`
+	NotificationID *uint     `json:"notification_id"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// parseJSONFeedback accepts a single event object or an array of them
+func parseJSONFeedback(body []byte) ([]models.EmailFeedback, error) {
+	var events []jsonFeedback
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		var event jsonFeedback
+		if err := json.Unmarshal(trimmed, &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	} else if err := json.Unmarshal(body, &events); err != nil {
+		return nil, err
+	}
+
+	var feedback []models.EmailFeedback
+	for _, event := range events {
+		item := models.EmailFeedback{
+			Recipient:      event.Recipient,
+			Type:           event.Type,
+			Status:         event.Status,
+			Reason:         event.Reason,
+			NotificationID: event.NotificationID,
+			OccurredAt:     event.OccurredAt,
+		}
+		switch event.Type {
+		case models.FeedbackBounce:
+			item.BounceType = event.BounceType
+			if item.BounceType != models.BounceSoft {
+				item.BounceType = models.BounceHard
+			}
+		case models.FeedbackComplaint:
+		default:
+			return nil, fmt.Errorf("unknown feedback type: %s", event.Type)
+		}
+		feedback = append(feedback, item)
+	}
+	return feedback, nil
+}
+
+// parseDSNFeedback classifies failed deliveries by their status class: 5.x.x is permanent
+func parseDSNFeedback(body []byte) ([]models.EmailFeedback, error) {
+	reports, err := mail.ParseReport(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var feedback []models.EmailFeedback
+	for _, report := range reports {
+		item := models.EmailFeedback{Recipient: report.Recipient, Status: report.Status, Reason: report.Diagnostic}
+		switch {
+		case report.Complaint():
+			item.Type = models.FeedbackComplaint
+			item.Reason = report.FeedbackType
+		case report.Action == "failed":
+			item.Type = models.FeedbackBounce
+			item.BounceType = models.BounceHard
+			if strings.HasPrefix(report.Status, "4") {
+				item.BounceType = models.BounceSoft
+			}
+		case report.Action == "delayed":
+			item.Type = models.FeedbackBounce
+			item.BounceType = models.BounceSoft
+		default:
+			continue
+		}
+		feedback = append(feedback, item)
+	}
+	return feedback, nil
+}
+
+func parseNotificationID(value string) *uint {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return nil
+	}
+	notificationID := uint(id)
+	return &notificationID
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/messaging"
+	"fmt"
+	"google.golang.org/api/option"
+	"sync"
+)
+
+// FCMClientProvider hands out a single lazily initialized Firebase messaging client
+type FCMClientProvider interface {
+	Client(ctx context.Context) (*messaging.Client, error)
+}
+
+type fcmClientProvider struct {
+	ProjectID string
+	FilePath  string
+	client    *messaging.Client
+	mu        sync.Mutex
+}
+
+func NewFCMClientProvider(filePath, projectID string) FCMClientProvider {
+	return &fcmClientProvider{FilePath: filePath, ProjectID: projectID}
+}
+
+func (p *fcmClientProvider) Client(ctx context.Context) (*messaging.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client != nil {
+		return p.client, nil
+	}
+
+	app, err := firebase.NewApp(ctx, &firebase.Config{ProjectID: p.ProjectID}, option.WithCredentialsFile(p.FilePath))
+	if err != nil {
+		return nil, fmt.Errorf("firebase init: %w", err)
+	}
+
+	client, err := app.Messaging(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("firebase client: %w", err)
+	}
+
+	p.client = client
+	return client, nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"firebase.google.com/go/v4/messaging"
+	"notification-service/internal/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAsset = "default"
+
+	priorityHigh   = "high"
+	priorityNormal = "normal"
+
+	// maxAPNSCollapseID is the APNs limit on apns-collapse-id in bytes
+	maxAPNSCollapseID = 64
+)
+
+// webpushTopic is the shape the Web Push protocol accepts in the Topic header
+var webpushTopic = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// buildMessage converts a request into an FCM message without an addressee. Only the
+// config for the request's platform is attached; an empty platform attaches all of them
+// so one message can serve a mixed set of devices or a topic.
+func buildMessage(request *models.NotificationRequest) *messaging.Message {
+	msg := &messaging.Message{
+		Data: buildData(request),
+	}
+	if !request.Silent {
+		msg.Notification = &messaging.Notification{
+			Title:    request.Title,
+			Body:     request.Body,
+			ImageURL: imageURL(request),
+		}
+	}
+
+	switch request.Platform {
+	case "android":
+		msg.Android = buildAndroidConfig(request)
+	case "ios":
+		msg.APNS = buildAPNSConfig(request)
+	case "web":
+		msg.Webpush = buildWebpushConfig(request)
+	default:
+		msg.Android = buildAndroidConfig(request)
+		msg.APNS = buildAPNSConfig(request)
+		msg.Webpush = buildWebpushConfig(request)
+	}
+	return msg
+}
+
+func buildAndroidConfig(request *models.NotificationRequest) *messaging.AndroidConfig {
+	config := &messaging.AndroidConfig{
+		Priority:    normalizePriority(request.Priority),
+		CollapseKey: request.CollapseKey,
+	}
+	// Without a notification block Android hands the data straight to the app's messaging service
+	if !request.Silent {
+		config.Notification = &messaging.AndroidNotification{
+			Title:       request.Title,
+			Body:        request.Body,
+			Color:       request.Color,
+			ClickAction: request.ClickAction,
+			Icon:        orDefault(request.Icon),
+			Sound:       orDefault(request.Sound),
+			ImageURL:    imageURL(request),
+			ChannelID:   request.ChannelID,
+		}
+	}
+	if ttl, ok := remainingTTL(request.ExpiresAt); ok {
+		config.TTL = &ttl
+	}
+	return config
+}
+
+func buildAPNSConfig(request *models.NotificationRequest) *messaging.APNSConfig {
+	headers := map[string]string{
+		"apns-priority": "10",
+	}
+	if normalizePriority(request.Priority) == priorityNormal {
+		headers["apns-priority"] = "5"
+	}
+	if request.ExpiresAt != nil {
+		headers["apns-expiration"] = strconv.FormatInt(request.ExpiresAt.Unix(), 10)
+	}
+	if request.CollapseKey != "" && len(request.CollapseKey) <= maxAPNSCollapseID {
+		headers["apns-collapse-id"] = request.CollapseKey
+	}
+
+	// Apple rejects background pushes sent with priority 10 and throttles them regardless
+	if request.Silent {
+		headers["apns-push-type"] = "background"
+		headers["apns-priority"] = "5"
+		return &messaging.APNSConfig{
+			Headers: headers,
+			Payload: &messaging.APNSPayload{
+				Aps: &messaging.Aps{ContentAvailable: true},
+			},
+		}
+	}
+
+	headers["apns-push-type"] = "alert"
+	config := &messaging.APNSConfig{
+		Headers: headers,
+		Payload: &messaging.APNSPayload{
+			Aps: &messaging.Aps{
+				Alert: &messaging.ApsAlert{
+					Title: request.Title,
+					Body:  request.Body,
+				},
+				Badge:          request.Badge,
+				Sound:          orDefault(request.Sound),
+				Category:       request.Category,
+				ThreadID:       request.ThreadID,
+				MutableContent: request.MutableContent,
+			},
+		},
+	}
+
+	// The notification service extension downloads the image, and only runs for mutable content
+	if image := imageURL(request); image != "" {
+		config.FCMOptions = &messaging.APNSFCMOptions{ImageURL: image}
+		config.Payload.Aps.MutableContent = true
+	}
+	return config
+}
+
+func buildWebpushConfig(request *models.NotificationRequest) *messaging.WebpushConfig {
+	headers := map[string]string{
+		"Urgency": normalizePriority(request.Priority),
+	}
+	if ttl, ok := remainingTTL(request.ExpiresAt); ok {
+		headers["TTL"] = strconv.FormatInt(int64(ttl/time.Second), 10)
+	}
+	if webpushTopic.MatchString(request.CollapseKey) {
+		headers["Topic"] = request.CollapseKey
+	}
+
+	config := &messaging.WebpushConfig{
+		Headers: headers,
+	}
+	// The service worker receives the data payload and decides whether to show anything
+	if request.Silent {
+		return config
+	}
+
+	config.Notification = &messaging.WebpushNotification{
+		Title: request.Title,
+		Body:  request.Body,
+		Image: imageURL(request),
+	}
+	for _, action := range request.Actions {
+		config.Notification.Actions = append(config.Notification.Actions, &messaging.WebpushNotificationAction{
+			Action: action.ID,
+			Title:  action.Title,
+			Icon:   action.Icon,
+		})
+	}
+
+	// Browsers need absolute URLs; the Android resource name "default" means nothing to them
+	if isURL(request.Icon) {
+		config.Notification.Icon = request.Icon
+	}
+	// FCM only accepts HTTPS links for web click-through
+	if strings.HasPrefix(request.ClickAction, "https://") {
+		config.FCMOptions = &messaging.WebpushFCMOptions{Link: request.ClickAction}
+	}
+	return config
+}
+
+// buildData adds the deep link and action buttons to the payload as JSON strings, since FCM
+// data values must be strings and Android apps render action buttons themselves
+func buildData(request *models.NotificationRequest) map[string]string {
+	if request.DeepLink == nil && len(request.Actions) == 0 {
+		return request.Payload
+	}
+
+	data := make(map[string]string, len(request.Payload)+2)
+	for key, value := range request.Payload {
+		data[key] = value
+	}
+	if request.DeepLink != nil {
+		data["deep_link"] = toJSON(request.DeepLink)
+	}
+	if len(request.Actions) > 0 {
+		data["actions"] = toJSON(request.Actions)
+	}
+	return data
+}
+
+// imageURL only passes HTTPS images through, which is all FCM will fetch
+func imageURL(request *models.NotificationRequest) string {
+	if strings.HasPrefix(request.ImageURL, "https://") {
+		return request.ImageURL
+	}
+	return ""
+}
+
+// normalizePriority maps stored priorities onto the two levels every platform understands,
+// keeping the historical default of high
+func normalizePriority(priority string) string {
+	switch strings.ToLower(priority) {
+	case priorityNormal, "low":
+		return priorityNormal
+	default:
+		return priorityHigh
+	}
+}
+
+// remainingTTL returns the time left until expiry, so a retried push does not outlive its original deadline
+func remainingTTL(expiresAt *time.Time) (time.Duration, bool) {
+	if expiresAt == nil {
+		return 0, false
+	}
+	ttl := time.Until(*expiresAt).Truncate(time.Second)
+	if ttl < 0 {
+		ttl = 0
+	}
+	return ttl, true
+}
+
+func orDefault(value string) string {
+	if value == "" {
+		return defaultAsset
+	}
+	return value
+}
+
+func isURL(value string) bool {
+	return strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://")
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"notification-service/internal/models"
+	"notification-service/internal/templates"
+)
+
+// emailLocale picks the event's locale, then the user's most recently seen device, then the profile
+func (s *notificationService) emailLocale(email models.Email) string {
+	return s.recipientLocale(email.Locale, email.UserID, email.To)
+}
+
+// recipientLocale resolves the locale of a message to one user for channels without devices of
+// their own: the event's locale, then the most recently seen device, then the profile
+func (s *notificationService) recipientLocale(locale string, userID uint, email string) string {
+	if locale != "" {
+		return locale
+	}
+
+	if userID != 0 {
+		devices, err := s.deviceRepo.FindActiveByUserID(userID)
+		if err != nil {
+			log.Printf("⚠️ Failed to load devices of user %d for locale: %v", userID, err)
+		}
+		var latest *models.Device
+		for i, device := range devices {
+			if device.Locale != "" && (latest == nil || device.LastSeenAt.After(latest.LastSeenAt)) {
+				latest = &devices[i]
+			}
+		}
+		if latest != nil {
+			return latest.Locale
+		}
+	}
+
+	return s.profileLocale(userID, email)
+}
+
+// profileLocale reads the locale the user last registered a device with, which outlives the
+// device itself; a failed lookup only costs the translation
+func (s *notificationService) profileLocale(userID uint, email string) string {
+	if userID == 0 && email != "" {
+		user, err := s.userRepo.FindByEmail(email)
+		if err != nil {
+			log.Printf("⚠️ Failed to load user profile for locale: %v", err)
+			return ""
+		}
+		if user != nil {
+			userID = user.UserID
+		}
+	}
+	if userID == 0 {
+		return ""
+	}
+
+	preference, err := s.userRepo.FindPreference(userID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load preferences of user %d for locale: %v", userID, err)
+		return ""
+	}
+	if preference == nil {
+		return ""
+	}
+	return preference.Locale
+}
+
+// pushLocalizer renders a push template once per locale for a single delivery, so fanning out
+// to many devices costs one render and one profile lookup per distinct locale and user
+type pushLocalizer struct {
+	s            *notificationService
+	notification models.NotificationResponse
+	rendered     map[string]*templates.Rendered
+	profiles     map[uint]string
+}
+
+func (s *notificationService) newPushLocalizer(notification models.NotificationResponse) *pushLocalizer {
+	return &pushLocalizer{
+		s:            s,
+		notification: notification,
+		rendered:     make(map[string]*templates.Rendered),
+		profiles:     make(map[uint]string),
+	}
+}
+
+// localize returns the notification with Title and Body rendered for the device's locale:
+// the event's locale, then the device's, then the user's profile
+func (l *pushLocalizer) localize(device *models.Device) (models.NotificationResponse, error) {
+	notification := l.notification
+	if notification.Template == "" {
+		return notification, nil
+	}
+
+	locale := notification.Locale
+	if locale == "" && device != nil {
+		locale = device.Locale
+		if locale == "" && device.UserID != 0 {
+			profile, ok := l.profiles[device.UserID]
+			if !ok {
+				profile = l.s.profileLocale(device.UserID, "")
+				l.profiles[device.UserID] = profile
+			}
+			locale = profile
+		}
+	}
+
+	rendered, ok := l.rendered[locale]
+	if !ok {
+		var err error
+		rendered, err = l.s.templates.RenderPush(notification.Template, locale, notification.Variables)
+		if err != nil {
+			return notification, fmt.Errorf("render push template %s: %w", notification.Template, err)
+		}
+		l.rendered[locale] = rendered
+	}
+
+	notification.Title = rendered.Title
+	notification.Body = rendered.Body
+	return notification, nil
+}
@@ -1,40 +1,82 @@
 package services
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	firebase "firebase.google.com/go/v4"
-	"firebase.google.com/go/v4/messaging"
+	"errors"
 	"fmt"
-	"google.golang.org/api/option"
-	"html/template"
 	"log"
-	"net/smtp"
+	"maps"
+	"net/http"
+	netmail "net/mail"
 	"notification-service/internal/models"
 	"notification-service/internal/repository"
+	"notification-service/internal/templates"
+	"notification-service/internal/utils"
+	"notification-service/internal/utils/chat"
+	"notification-service/internal/utils/mail"
+	"notification-service/internal/utils/sms"
+	"slices"
 	"time"
 )
 
 type NotificationService interface {
 	SendNotificationAuthentication(data []byte) error
 	SendNotificationEmail(data []byte) error
+	SendNotificationSMS(data []byte) error
+	SendNotificationWebhook(data []byte) error
+	SendNotificationChat(data []byte) error
 	SendNotificationAsset(data []byte) error
 	SendNotification(notif *models.NotificationRequest) error
+	SetEventPublisher(publisher EventPublisher)
+	RetryPending() error
 }
 
+// EventPublisher emits events for other services; the NATS service satisfies it
+type EventPublisher interface {
+	Publish(subject string, data interface{}) error
+}
+
+// ErrInvalidToken marks FCM rejections meaning the registration token will never be deliverable
+var ErrInvalidToken = errors.New("invalid registration token")
+
+// errUndeliverable marks failures that retrying cannot fix, such as a template that does not render
+var errUndeliverable = errors.New("undeliverable")
+
 type notificationService struct {
-	repo      repository.NotificationRepository
-	ProjectID string
-	FilePath  string
-	SMTPHost  string
-	SMTPPort  string
-	Email     string
-	Password  string
+	repo       repository.NotificationRepository
+	deviceRepo repository.DeviceRepository
+	userRepo   repository.UserRepository
+	suppressed repository.SuppressionRepository
+	webhooks   repository.WebhookRepository
+	chats      repository.ChatRepository
+	publisher  EventPublisher
+	fcm        FCMClientProvider
+	maxRetries int
+	templates  templates.Registry
+	mailer     mail.Transport
+	tracker    TrackingService
+	sms        sms.Provider
+	chat       chat.Sender
+	Email      string
+	FromName   string
+
+	attachmentLimits AttachmentLimits
+	smsMaxSegments   int
+	webhookClient    *http.Client
+	webhookJobs      chan webhookJob
+}
+
+func NewNotificationService(repo repository.NotificationRepository, deviceRepo repository.DeviceRepository, userRepo repository.UserRepository, suppressionRepo repository.SuppressionRepository, webhookRepo repository.WebhookRepository, chatRepo repository.ChatRepository, fcm FCMClientProvider, registry templates.Registry, mailer mail.Transport, tracker TrackingService, smsProvider sms.Provider, chatSender chat.Sender, email, fromName string, attachmentLimits AttachmentLimits, smsMaxSegments int, webhookTimeout time.Duration, maxRetries int) NotificationService {
+	s := &notificationService{repo: repo, deviceRepo: deviceRepo, userRepo: userRepo, suppressed: suppressionRepo, webhooks: webhookRepo, chats: chatRepo, fcm: fcm, templates: registry, mailer: mailer, tracker: tracker, sms: smsProvider, chat: chatSender, Email: email, FromName: fromName, attachmentLimits: attachmentLimits, smsMaxSegments: smsMaxSegments, webhookClient: utils.NewPublicHTTPClient(webhookTimeout), webhookJobs: make(chan webhookJob, webhookQueueSize(webhookTimeout)), maxRetries: maxRetries}
+	for range maxConcurrentWebhooks {
+		go s.runWebhookWorker()
+	}
+	return s
 }
 
-func NewNotificationService(repo repository.NotificationRepository, filePath, id, smtpHost, smtpPort, email, password string) NotificationService {
-	return &notificationService{repo: repo, FilePath: filePath, ProjectID: id, SMTPHost: smtpHost, SMTPPort: smtpPort, Email: email, Password: password}
+func (s *notificationService) SetEventPublisher(publisher EventPublisher) {
+	s.publisher = publisher
 }
 
 func (s *notificationService) SendNotificationAuthentication(data []byte) error {
@@ -53,239 +95,264 @@ func (s *notificationService) SendNotificationAuthentication(data []byte) error
 		return fmt.Errorf("unmarshal token details: %w", err)
 	}
 
-	log.Printf("payloadJSON message : %s", payloadJSON)
-	log.Printf("tokenDetails message : %s", tokenDetails)
-
 	payload := map[string]string{
 		"type":          "system",
 		"access_token":  tokenDetails.AccessToken,
 		"refresh_token": tokenDetails.RefreshToken,
 	}
-	log.Printf("payload message : %s", payload)
-
-	now := time.Now()
-	notifReq := &models.NotificationRequest{
-		TargetToken: notification.TargetToken,
-		Title:       notification.Title,
-		Body:        notification.Body,
-		Priority:    notification.Priority,
-		Color:       notification.Color,
-		ClickAction: notification.ClickAction,
-		Payload:     payload,
-	}
-
-	notif := models.Notification{
-		TargetToken:   notification.TargetToken,
-		Title:         notification.Title,
-		Body:          notification.Body,
-		Platform:      notification.Platform,
-		CreatedAt:     now,
-		ServiceSource: notification.ServiceSource,
-		EventType:     notification.EventType,
-		ClickAction:   notification.ClickAction,
-		Priority:      notification.Priority,
-		Color:         notification.Color,
-		Payload:       toJSONString(payload),
-		Status:        "pending",
-	}
-
-	if err := s.repo.Save(&notif); err != nil {
-		return fmt.Errorf("save notification: %w", err)
-	}
 
-	if err := s.SendNotification(notifReq); err != nil {
-		return fmt.Errorf("send notification: %w", err)
-	}
+	// Token refreshes are consumed by the app in the background and must never show a banner
+	notification.Silent = true
 
-	notif.Status = "sent"
-	notif.SentAt = &now
-	return s.repo.Update(&notif)
+	return s.deliver(notification, payload)
 }
 
+// SendNotificationEmail stores the email as a pending notification row before sending it, so
+// failed sends are retried by the sweeper and every email keeps a status and history.
+// Inline attachment content is stored beside the row rather than in its payload.
 func (s *notificationService) SendNotificationEmail(data []byte) error {
 	var email models.Email
 	if err := json.Unmarshal(data, &email); err != nil {
 		return fmt.Errorf("unmarshal notification: %w", err)
 	}
 
-	to := []string{email.To}
-
-	subject := "Subject: Reset Your Password\n"
-	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
-
-	// Define the HTML template
-	htmlTemplate := `
-		<!DOCTYPE html>
-		<html lang="en">
-		<head>
-		  <meta charset="UTF-8">
-		  <title>Forgot Your Password?</title>
-		  <style>
-			body {
-			  font-family: Arial, sans-serif;
-			  background-color: #f4f4f4;
-			  margin: 0;
-			  padding: 20px;
-			}
-			.container {
-			  max-width: 600px;
-			  margin: auto;
-			  background-color: #ffffff;
-			  padding: 30px;
-			  border-radius: 8px;
-			  box-shadow: 0 2px 8px rgba(0, 0, 0, 0.05);
-			}
-			.button {
-			  display: inline-block;
-			  margin-top: 20px;
-			  padding: 12px 24px;
-			  background-color: #1e88e5;
-			  color: #ffffff;
-			  text-decoration: none;
-			  border-radius: 5px;
-			  font-weight: bold;
-			}
-			.footer {
-			  color: #999;
-			  font-size: 12px;
-			  margin-top: 30px;
-			}
-		  </style>
-		</head>
-		<body>
-		  <div class="container">
-			<h2 style="color: #333;">Hi {{.FullName}},</h2>
-			<p style="color: #555;">We received a request to reset the password for your account.</p>
-			<p style="color: #555;">To continue, please click the button below. You’ll be redirected to our app to set a new password:</p>
-			<a href="{{.URL}}" class="button">Reset Your Password</a>
-			<p class="footer">If you didn't request this, you can safely ignore this email. Your password will remain unchanged.</p>
-			  </div>
-			</body>
-			</html>
-			`
-
-	// Use html/template to inject dynamic values
-	tmpl, err := template.New("email").Parse(htmlTemplate)
+	payload := string(data)
+	stripped, contents := splitAttachmentContents(email)
+	if len(contents) > 0 {
+		encoded, err := json.Marshal(stripped)
+		if err != nil {
+			return fmt.Errorf("marshal email: %w", err)
+		}
+		payload = string(encoded)
+	}
+
+	notif := &models.Notification{
+		Channel:      models.ChannelEmail,
+		Recipient:    email.To,
+		TemplateName: emailTemplateName(email),
+		EventType:    email.EventType,
+		Payload:      payload,
+		Status:       "pending",
+		CreatedAt:    time.Now(),
+	}
+	if email.UserID != 0 {
+		userID := email.UserID
+		notif.UserID = &userID
+	}
+	if err := s.repo.Save(notif); err != nil {
+		return fmt.Errorf("save notification: %w", err)
+	}
+	if len(contents) > 0 {
+		for i := range contents {
+			contents[i].NotificationID = notif.ID
+		}
+		if err := s.repo.SaveAttachmentContents(contents); err != nil {
+			return fmt.Errorf("save attachment contents: %w", err)
+		}
+	}
+
+	return s.finishEmail(notif, s.sendEmail(notif, email))
+}
+
+// finishEmail records the outcome of a send and drops the stored attachment content once the
+// row will not be retried
+func (s *notificationService) finishEmail(notif *models.Notification, sendErr error) error {
+	err := s.recordResult(notif, sendErr)
+	if notif.Status != "pending" {
+		s.dropAttachmentContents(notif)
+	}
+	return err
+}
+
+func (s *notificationService) dropAttachmentContents(notif *models.Notification) {
+	if err := s.repo.DeleteAttachmentContents(notif.ID); err != nil {
+		log.Printf("⚠️ Failed to delete attachment contents of notification %d: %v", notif.ID, err)
+	}
+}
+
+// splitAttachmentContents returns the email without inline attachment content, and that content
+func splitAttachmentContents(email models.Email) (models.Email, []models.EmailAttachmentContent) {
+	var contents []models.EmailAttachmentContent
+	attachments := make([]models.EmailAttachment, len(email.Attachments))
+	for i, attachment := range email.Attachments {
+		if attachment.Content != "" {
+			contents = append(contents, models.EmailAttachmentContent{Position: i, Content: attachment.Content})
+			attachment.Content = ""
+		}
+		attachments[i] = attachment
+	}
+	email.Attachments = attachments
+	return email, contents
+}
+
+// sendEmail renders and sends the email of a stored row; rendering and attachment errors will
+// not go away on retry, so they are marked undeliverable
+func (s *notificationService) sendEmail(notif *models.Notification, email models.Email) error {
+	suppression, err := s.suppressed.FindSuppression(normalizeEmail(email.To))
 	if err != nil {
-		return fmt.Errorf("template parse error: %w", err)
+		return fmt.Errorf("find suppression: %w", err)
+	}
+	if suppression != nil {
+		return fmt.Errorf("%w: %s is suppressed after %s", errUndeliverable, email.To, suppression.Reason)
 	}
 
-	var body bytes.Buffer
-	if err := tmpl.Execute(&body, struct {
-		FullName string
-		URL      string
-	}{
-		FullName: email.FullName,
-		URL:      email.URL,
-	}); err != nil {
-		return fmt.Errorf("template execution error: %w", err)
+	variables, err := emailVariables(email)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errUndeliverable, err)
 	}
 
-	// Final email message
-	message := []byte(subject + mime + body.String())
+	rendered, err := s.templates.RenderEmail(notif.TemplateName, s.emailLocale(email), variables)
+	if err != nil {
+		return fmt.Errorf("%w: render email: %w", errUndeliverable, err)
+	}
 
-	// Auth
-	auth := smtp.PlainAuth("", s.Email, s.Password, s.SMTPHost)
+	subject := rendered.Subject
+	if email.Subject != "" {
+		subject = email.Subject
+	}
+	notif.Subject = subject
+	notif.Title = subject
 
-	// Send
-	if err := smtp.SendMail(s.SMTPHost+":"+s.SMTPPort, auth, s.Email, to, message); err != nil {
+	attachments, err := s.loadAttachments(email.Attachments)
+	if errors.Is(err, ErrAttachmentTooLarge) {
+		return fmt.Errorf("%w: %w", errUndeliverable, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	message := &mail.Message{
+		From:        netmail.Address{Name: s.FromName, Address: s.Email},
+		To:          []netmail.Address{{Name: email.FullName, Address: email.To}},
+		Subject:     subject,
+		HTML:        rendered.HTML,
+		Text:        rendered.Text,
+		Attachments: attachments,
+	}
+	if s.tracked(notif.TemplateName, email) {
+		// The plain-text part keeps the original links
+		if message.Text == "" {
+			message.Text = mail.HTMLToText(message.HTML)
+		}
+		message.HTML = s.tracker.Instrument(notif.ID, message.HTML)
+	}
+	if email.UnsubscribeURL != "" {
+		message.ListUnsubscribe = []string{email.UnsubscribeURL}
+	}
+
+	if err := s.mailer.Send(message); err != nil {
+		if errors.Is(err, mail.ErrRejected) {
+			return fmt.Errorf("%w: send email: %w", errUndeliverable, err)
+		}
 		return fmt.Errorf("send email: %w", err)
 	}
 
-	log.Println("✅ Email sent successfully to", email.To)
+	log.Printf("✅ Email %s sent successfully to %s", notif.TemplateName, email.To)
 	return nil
 }
 
-func (s *notificationService) SendNotificationAsset(data []byte) error {
-	var notification models.NotificationResponse
-	if err := json.Unmarshal(data, &notification); err != nil {
-		return fmt.Errorf("unmarshal notification: %w", err)
+// tracked reports whether opens and clicks of the email are tracked: the event's track flag
+// decides, and without one only non-transactional templates are tracked
+func (s *notificationService) tracked(name string, email models.Email) bool {
+	if s.tracker == nil || !s.tracker.Enabled() {
+		return false
 	}
-
-	if notification.EventType != "assign_user_resource" && notification.EventType != "remove_user_resource" {
-		return fmt.Errorf("unsupported event type: %s", notification.EventType)
+	if email.Track != nil {
+		return *email.Track
 	}
+	return !templates.IsTransactional(name)
+}
 
-	now := time.Now()
-	notifReq := &models.NotificationRequest{
-		TargetToken: notification.TargetToken,
-		Title:       notification.Title,
-		Body:        notification.Body,
-		Priority:    notification.Priority,
-		Color:       notification.Color,
-		ClickAction: notification.ClickAction,
-		Payload:     notification.Payload,
+// emailEventTemplates maps producer event types onto the template they render
+var emailEventTemplates = map[string]string{
+	"forgot_password":    templates.PasswordReset,
+	"user_registered":    templates.Welcome,
+	"email_verification": templates.EmailVerification,
+	"asset_report":       templates.AssetReport,
+}
+
+// emailTemplateName picks the explicit template, then the event's template, then password reset,
+// which is what producers sent before templates were selectable
+func emailTemplateName(email models.Email) string {
+	if email.Template != "" {
+		return email.Template
 	}
+	if name, ok := emailEventTemplates[email.EventType]; ok {
+		return name
+	}
+	return templates.PasswordReset
+}
 
-	notif := models.Notification{
-		TargetToken:   notification.TargetToken,
-		Title:         notification.Title,
-		Body:          notification.Body,
-		ServiceSource: notification.ServiceSource,
-		EventType:     notification.EventType,
-		Priority:      notification.Priority,
-		Color:         notification.Color,
-		Payload:       toJSONString(notification.Payload),
-		Status:        "pending",
+// emailVariables merges the legacy top-level full_name and url into the template variables
+func emailVariables(email models.Email) (json.RawMessage, error) {
+	variables := map[string]interface{}{}
+	if len(email.Variables) > 0 {
+		if err := json.Unmarshal(email.Variables, &variables); err != nil {
+			return nil, fmt.Errorf("unmarshal email variables: %w", err)
+		}
 	}
+	if _, ok := variables["full_name"]; !ok && email.FullName != "" {
+		variables["full_name"] = email.FullName
+	}
+	if _, ok := variables["url"]; !ok && email.URL != "" {
+		variables["url"] = email.URL
+	}
+	return json.Marshal(variables)
+}
 
-	if err := s.repo.Save(&notif); err != nil {
-		return fmt.Errorf("save notification: %w", err)
+func (s *notificationService) SendNotificationAsset(data []byte) error {
+	var notification models.NotificationResponse
+	if err := json.Unmarshal(data, &notification); err != nil {
+		return fmt.Errorf("unmarshal notification: %w", err)
 	}
 
-	if err := s.SendNotification(notifReq); err != nil {
-		return fmt.Errorf("send notification: %w", err)
+	if notification.EventType != "assign_user_resource" && notification.EventType != "remove_user_resource" {
+		return fmt.Errorf("unsupported event type: %s", notification.EventType)
 	}
 
-	notif.Status = "sent"
-	notif.SentAt = &now
-	return s.repo.Update(&notif)
+	return s.deliver(notification, notification.Payload)
 }
 
 func (s *notificationService) SendNotification(request *models.NotificationRequest) error {
 	ctx := context.Background()
 
-	app, err := firebase.NewApp(ctx, &firebase.Config{ProjectID: s.ProjectID}, option.WithCredentialsFile(s.FilePath))
+	client, err := s.fcm.Client(ctx)
 	if err != nil {
-		return fmt.Errorf("firebase init: %w", err)
+		return err
 	}
 
-	client, err := app.Messaging(ctx)
-	if err != nil {
-		return fmt.Errorf("firebase client: %w", err)
-	}
-
-	msg := &messaging.Message{
-		Token: request.TargetToken,
-		Data:  request.Payload,
-		Android: &messaging.AndroidConfig{
-			Priority: "high",
-			Notification: &messaging.AndroidNotification{
-				Title:       request.Title,
-				Body:        request.Body,
-				Color:       request.Color,
-				ClickAction: request.ClickAction,
-				Icon:        "default",
-				Sound:       "default",
-			},
-		},
-		Notification: &messaging.Notification{
-			Title: request.Title,
-			Body:  request.Body,
-		},
-	}
-
-	log.Printf("📤 Sending FCM with payload: %+v", request.Payload)
+	msg := buildMessage(request)
+	switch {
+	case request.Condition != "":
+		msg.Condition = request.Condition
+	case request.Topic != "":
+		msg.Topic = request.Topic
+	default:
+		msg.Token = request.TargetToken
+	}
+
+	// Payload values can be access and refresh tokens, so only the keys are logged
+	log.Printf("📤 Sending FCM with payload keys: %v", slices.Sorted(maps.Keys(request.Payload)))
 
 	resp, err := client.Send(ctx, msg)
 	if err != nil {
-		return fmt.Errorf("FCM send: %w", err)
+		return fcmSendError(err)
 	}
 
 	log.Printf("✅ FCM sent: %s", resp)
 	return nil
 }
 
+// toJSON marshals structured columns, falling back to an empty string when the value cannot be encoded
+func toJSON(v interface{}) string {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("⚠️ Failed to marshal value: %v", err)
+		return ""
+	}
+	return string(jsonBytes)
+}
+
 func toJSONString(data map[string]string) string {
 	jsonBytes, err := json.Marshal(data)
 	if err != nil {
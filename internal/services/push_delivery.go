@@ -0,0 +1,511 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"firebase.google.com/go/v4/messaging"
+	"fmt"
+	"log"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+	"time"
+)
+
+// maxMulticastTokens is the FCM limit on tokens per SendEachForMulticast call
+const maxMulticastTokens = 500
+
+// pushDelivery pairs a stored notification row with the device it is addressed to
+type pushDelivery struct {
+	device models.Device
+	notif  *models.Notification
+}
+
+// resolveTargets collects the explicit target tokens and every active device of the target users
+func (s *notificationService) resolveTargets(notification models.NotificationResponse) ([]models.Device, error) {
+	var devices []models.Device
+	seen := make(map[string]bool)
+	add := func(device models.Device) {
+		if device.Token == "" || seen[device.Token] {
+			return
+		}
+		seen[device.Token] = true
+		devices = append(devices, device)
+	}
+
+	add(models.Device{Token: notification.TargetToken, Platform: notification.Platform, UserID: notification.UserID})
+	for _, token := range notification.TargetTokens {
+		add(models.Device{Token: token, Platform: notification.Platform})
+	}
+
+	userIDs := notification.UserIDs
+	if notification.TargetToken == "" && notification.UserID != 0 {
+		userIDs = append([]uint{notification.UserID}, userIDs...)
+	}
+	for _, userID := range userIDs {
+		userDevices, err := s.deviceRepo.FindActiveByUserID(userID)
+		if err != nil {
+			return nil, fmt.Errorf("find devices: %w", err)
+		}
+		for _, device := range userDevices {
+			add(device)
+		}
+	}
+
+	if len(devices) == 0 {
+		return nil, errors.New("notification has no deliverable target_token, target_tokens, user_id or user_ids")
+	}
+	return devices, nil
+}
+
+// deliver stores one notification row per target device, then sends to a single device
+// directly or to many devices through multicast calls chunked per platform
+func (s *notificationService) deliver(notification models.NotificationResponse, payload map[string]string) error {
+	localizer := s.newPushLocalizer(notification)
+	if notification.Topic != "" || notification.Condition != "" {
+		localized, err := localizer.localize(nil)
+		if err != nil {
+			return err
+		}
+		return s.deliverToTopic(localized, payload)
+	}
+
+	devices, err := s.resolveTargets(notification)
+	if err != nil {
+		return err
+	}
+
+	suppressedTokens, err := s.suppressedTokens(devices)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	var pending []pushDelivery
+	for _, device := range devices {
+		localized, err := localizer.localize(&device)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		notif := newNotificationRow(localized, payload)
+		notif.TargetToken = device.Token
+		notif.Platform = device.Platform
+		if device.UserID != 0 {
+			userID := device.UserID
+			notif.UserID = &userID
+		}
+
+		suppressed := suppressedTokens[device.Token]
+		if suppressed {
+			errMsg := "token invalidated"
+			notif.Status = "failed"
+			notif.LastError = &errMsg
+		}
+
+		if err := s.repo.Save(notif); err != nil {
+			errs = append(errs, fmt.Errorf("save notification: %w", err))
+			continue
+		}
+		if suppressed {
+			log.Printf("⛔ Skipping notification %d to invalidated token", notif.ID)
+			continue
+		}
+		pending = append(pending, pushDelivery{device: device, notif: notif})
+	}
+
+	if len(pending) == 1 {
+		if err := s.recordPushResult(pending[0], s.SendNotification(requestFromRow(pending[0].notif))); err != nil {
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+
+	// Group by platform so each multicast carries only the config its devices understand,
+	// and by content since templated pushes differ per locale
+	type groupKey struct{ platform, title, body string }
+	groups := make(map[groupKey][]pushDelivery)
+	var keys []groupKey
+	for _, delivery := range pending {
+		key := groupKey{delivery.notif.Platform, delivery.notif.Title, delivery.notif.Body}
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], delivery)
+	}
+
+	for _, key := range keys {
+		group := groups[key]
+		request := requestFromRow(group[0].notif)
+		for start := 0; start < len(group); start += maxMulticastTokens {
+			end := min(start+maxMulticastTokens, len(group))
+			errs = append(errs, s.sendMulticast(request, group[start:end])...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliverToTopic stores a single row for a topic or condition send; FCM handles the fan-out
+func (s *notificationService) deliverToTopic(notification models.NotificationResponse, payload map[string]string) error {
+	notif := newNotificationRow(notification, payload)
+	notif.Topic = notification.Topic
+	notif.Condition = notification.Condition
+	if err := s.repo.Save(notif); err != nil {
+		return fmt.Errorf("save notification: %w", err)
+	}
+
+	return s.recordPushResult(pushDelivery{notif: notif}, s.SendNotification(requestFromRow(notif)))
+}
+
+// newNotificationRow maps an incoming event onto a pending row; the addressee is filled in by the caller
+func newNotificationRow(notification models.NotificationResponse, payload map[string]string) *models.Notification {
+	notif := &models.Notification{
+		Channel:        models.ChannelPush,
+		Title:          notification.Title,
+		Body:           notification.Body,
+		Platform:       notification.Platform,
+		CreatedAt:      time.Now(),
+		ServiceSource:  notification.ServiceSource,
+		EventType:      notification.EventType,
+		ClickAction:    notification.ClickAction,
+		Priority:       notification.Priority,
+		Color:          notification.Color,
+		Icon:           notification.Icon,
+		Sound:          notification.Sound,
+		Badge:          notification.Badge,
+		Category:       notification.Category,
+		ThreadID:       notification.ThreadID,
+		MutableContent: notification.MutableContent,
+		CollapseKey:    notification.CollapseKey,
+		Silent:         notification.Silent,
+		ImageURL:       notification.ImageURL,
+		ChannelID:      notification.ChannelID,
+		Payload:        toJSONString(payload),
+		Status:         "pending",
+	}
+	if len(notification.Actions) > 0 {
+		notif.Actions = toJSON(notification.Actions)
+	}
+	if notification.DeepLink != nil {
+		notif.DeepLink = toJSON(notification.DeepLink)
+	}
+	if notification.TTL > 0 {
+		expiresAt := notif.CreatedAt.Add(time.Duration(notification.TTL) * time.Second)
+		notif.ExpiresAt = &expiresAt
+	}
+	return notif
+}
+
+// requestFromRow rebuilds the send request from a stored row, so first sends and retries match
+func requestFromRow(notif *models.Notification) *models.NotificationRequest {
+	payload := make(map[string]string)
+	if notif.Payload != "" {
+		if err := json.Unmarshal([]byte(notif.Payload), &payload); err != nil {
+			log.Printf("⚠️ Failed to unmarshal payload of notification %d: %v", notif.ID, err)
+		}
+	}
+
+	request := &models.NotificationRequest{
+		TargetToken:    notif.TargetToken,
+		Topic:          notif.Topic,
+		Condition:      notif.Condition,
+		Title:          notif.Title,
+		Body:           notif.Body,
+		Payload:        payload,
+		Color:          notif.Color,
+		Priority:       notif.Priority,
+		ClickAction:    notif.ClickAction,
+		Platform:       notif.Platform,
+		Icon:           notif.Icon,
+		Sound:          notif.Sound,
+		Badge:          notif.Badge,
+		Category:       notif.Category,
+		ThreadID:       notif.ThreadID,
+		MutableContent: notif.MutableContent,
+		CollapseKey:    notif.CollapseKey,
+		ExpiresAt:      notif.ExpiresAt,
+		Silent:         notif.Silent,
+		ImageURL:       notif.ImageURL,
+		ChannelID:      notif.ChannelID,
+	}
+	if notif.Actions != "" {
+		if err := json.Unmarshal([]byte(notif.Actions), &request.Actions); err != nil {
+			log.Printf("⚠️ Failed to unmarshal actions of notification %d: %v", notif.ID, err)
+		}
+	}
+	if notif.DeepLink != "" {
+		var deepLink models.DeepLink
+		if err := json.Unmarshal([]byte(notif.DeepLink), &deepLink); err != nil {
+			log.Printf("⚠️ Failed to unmarshal deep link of notification %d: %v", notif.ID, err)
+		} else {
+			request.DeepLink = &deepLink
+		}
+	}
+	return request
+}
+
+// sendMulticast sends one chunk and records each token's outcome against its own row
+func (s *notificationService) sendMulticast(request *models.NotificationRequest, chunk []pushDelivery) []error {
+	ctx := context.Background()
+	tokens := make([]string, len(chunk))
+	for i, delivery := range chunk {
+		tokens[i] = delivery.device.Token
+	}
+
+	var errs []error
+	client, err := s.fcm.Client(ctx)
+	if err == nil {
+		msg := buildMessage(request)
+		var batch *messaging.BatchResponse
+		batch, err = client.SendEachForMulticast(ctx, &messaging.MulticastMessage{
+			Tokens:       tokens,
+			Data:         msg.Data,
+			Notification: msg.Notification,
+			Android:      msg.Android,
+			Webpush:      msg.Webpush,
+			APNS:         msg.APNS,
+			FCMOptions:   msg.FCMOptions,
+		})
+		if err == nil {
+			log.Printf("✅ FCM multicast: %d sent, %d failed", batch.SuccessCount, batch.FailureCount)
+			for i, resp := range batch.Responses {
+				var sendErr error
+				if !resp.Success {
+					sendErr = fcmSendError(resp.Error)
+				}
+				if err := s.recordPushResult(chunk[i], sendErr); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return errs
+		}
+		err = fmt.Errorf("FCM multicast: %w", err)
+	}
+
+	// The whole chunk failed before FCM produced per-token results
+	for _, delivery := range chunk {
+		if recordErr := s.recordPushResult(delivery, err); recordErr != nil {
+			errs = append(errs, recordErr)
+		}
+	}
+	return errs
+}
+
+// recordPushResult records the outcome of a push send, pruning the device when FCM reports
+// its token as invalid
+func (s *notificationService) recordPushResult(delivery pushDelivery, sendErr error) error {
+	if errors.Is(sendErr, ErrInvalidToken) && delivery.device.Token != "" {
+		s.invalidateToken(delivery.device, sendErr.Error())
+		sendErr = fmt.Errorf("%w: %w", errUndeliverable, sendErr)
+	}
+	return s.recordResult(delivery.notif, sendErr)
+}
+
+// fcmSendError classifies an FCM failure. Only unregistered tokens and tokens of another sender
+// are pruned. FCM also answers invalid-argument for bad message content, such as an oversized
+// data payload or a producer's malformed image URL, color or condition, so that fails the row
+// alone and leaves the device as it is.
+func fcmSendError(err error) error {
+	switch {
+	case messaging.IsUnregistered(err) || messaging.IsSenderIDMismatch(err):
+		return fmt.Errorf("FCM send: %w: %w", ErrInvalidToken, err)
+	case messaging.IsInvalidArgument(err):
+		return fmt.Errorf("FCM send: %w: %w", errUndeliverable, err)
+	default:
+		return fmt.Errorf("FCM send: %w", err)
+	}
+}
+
+// isTokenSuppressed reports whether the registry has marked the token undeliverable
+func (s *notificationService) isTokenSuppressed(device models.Device) (bool, error) {
+	suppressed, err := s.suppressedTokens([]models.Device{device})
+	if err != nil {
+		return false, err
+	}
+	return suppressed[device.Token], nil
+}
+
+// suppressedTokens returns the tokens the registry has marked undeliverable. Registry devices
+// carry their own state; explicit tokens are looked up one query per multicast-sized chunk.
+func (s *notificationService) suppressedTokens(devices []models.Device) (map[string]bool, error) {
+	suppressed := make(map[string]bool)
+	var lookup []string
+	for _, device := range devices {
+		if device.ID != 0 {
+			if device.InvalidatedAt != nil {
+				suppressed[device.Token] = true
+			}
+			continue
+		}
+		lookup = append(lookup, device.Token)
+	}
+
+	for start := 0; start < len(lookup); start += maxMulticastTokens {
+		end := min(start+maxMulticastTokens, len(lookup))
+		registered, err := s.deviceRepo.FindByTokens(lookup[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("find devices: %w", err)
+		}
+		for _, device := range registered {
+			if device.InvalidatedAt != nil {
+				suppressed[device.Token] = true
+			}
+		}
+	}
+	return suppressed, nil
+}
+
+// invalidateToken records the token as undeliverable and lets the auth service clear it from the user
+func (s *notificationService) invalidateToken(device models.Device, reason string) {
+	now := time.Now()
+	if device.ID == 0 {
+		registered, err := s.deviceRepo.FindByToken(device.Token)
+		if err != nil {
+			log.Printf("⚠️ Failed to look up invalid token: %v", err)
+			return
+		}
+		if registered == nil {
+			// An explicit token nobody registered has no owner to tell; failing its row is enough
+			return
+		}
+		device = *registered
+	}
+
+	device.IsActive = false
+	device.InvalidatedAt = &now
+	device.InvalidReason = &reason
+	if err := s.deviceRepo.Update(&device); err != nil {
+		log.Printf("⚠️ Failed to mark device %d invalid: %v", device.ID, err)
+		return
+	} else if err := s.deviceRepo.RemoveTopics(device.ID); err != nil {
+		// FCM drops an unregistered token's memberships itself; only the local rows are left
+		log.Printf("⚠️ Failed to remove topics of device %d: %v", device.ID, err)
+	}
+
+	log.Printf("🧹 Device token for user %d marked invalid: %s", device.UserID, reason)
+	if s.publisher == nil || device.UserID == 0 {
+		return
+	}
+
+	event := models.DeviceTokenInvalidatedEvent{
+		UserID:        device.UserID,
+		Token:         device.Token,
+		Reason:        reason,
+		InvalidatedAt: now,
+	}
+	if err := s.publisher.Publish(utils.SubjectDeviceTokenInvalidated, event); err != nil {
+		log.Printf("⚠️ Failed to publish token invalidation: %v", err)
+	}
+}
+
+// RetryPending resends pending notifications whose backoff has elapsed, expiring those past their
+// TTL and failing those that ran out of attempts. Every replica runs it; rows are claimed first so
+// each one is sent by a single replica.
+func (s *notificationService) RetryPending() error {
+	now := time.Now()
+	notifications, err := s.repo.ClaimRetryableNotifications(now.Add(-retryGracePeriod), now, now.Add(retryLease), retryBatchSize)
+	if err != nil {
+		return fmt.Errorf("get retryable notifications: %w", err)
+	}
+
+	var errs []error
+	for i := range notifications {
+		notif := &notifications[i]
+		if notif.ExpiresAt != nil && now.After(*notif.ExpiresAt) {
+			notif.Status = "expired"
+			if err := s.repo.Update(notif); err != nil {
+				errs = append(errs, fmt.Errorf("expire notification %d: %w", notif.ID, err))
+			}
+			continue
+		}
+		if notif.RetryCount >= s.maxRetries {
+			notif.Status = "failed"
+			if err := s.repo.Update(notif); err != nil {
+				errs = append(errs, fmt.Errorf("fail notification %d: %w", notif.ID, err))
+			}
+			if notif.Channel == models.ChannelEmail {
+				s.dropAttachmentContents(notif)
+			}
+			continue
+		}
+
+		notif.RetryCount++
+		switch notif.Channel {
+		case models.ChannelEmail:
+			if err := s.finishEmail(notif, s.retryEmail(notif)); err != nil {
+				errs = append(errs, fmt.Errorf("retry notification %d: %w", notif.ID, err))
+			}
+			continue
+		case models.ChannelSMS:
+			if err := s.recordResult(notif, s.retrySMS(notif)); err != nil {
+				errs = append(errs, fmt.Errorf("retry notification %d: %w", notif.ID, err))
+			}
+			continue
+		case models.ChannelWebhook:
+			if err := s.recordResult(notif, s.retryWebhook(notif)); err != nil {
+				errs = append(errs, fmt.Errorf("retry notification %d: %w", notif.ID, err))
+			}
+			continue
+		case models.ChannelChat:
+			if err := s.recordResult(notif, s.retryChat(notif)); err != nil {
+				errs = append(errs, fmt.Errorf("retry notification %d: %w", notif.ID, err))
+			}
+			continue
+		}
+
+		delivery := pushDelivery{notif: notif}
+		if notif.TargetToken != "" {
+			delivery.device = models.Device{Token: notif.TargetToken, Platform: notif.Platform}
+			if notif.UserID != nil {
+				delivery.device.UserID = *notif.UserID
+			}
+
+			// The token may have been invalidated after the row was queued
+			suppressed, err := s.isTokenSuppressed(delivery.device)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("retry notification %d: %w", notif.ID, err))
+				continue
+			}
+			if suppressed {
+				errMsg := "token invalidated"
+				notif.Status = "failed"
+				notif.LastError = &errMsg
+				if err := s.repo.Update(notif); err != nil {
+					errs = append(errs, fmt.Errorf("fail notification %d: %w", notif.ID, err))
+				}
+				continue
+			}
+		}
+		if err := s.recordPushResult(delivery, s.SendNotification(requestFromRow(notif))); err != nil {
+			errs = append(errs, fmt.Errorf("retry notification %d: %w", notif.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// retryEmail rebuilds the email from the event stored in the row's payload, restoring inline
+// attachment content from where it was stored beside the row
+func (s *notificationService) retryEmail(notif *models.Notification) error {
+	var email models.Email
+	if err := json.Unmarshal([]byte(notif.Payload), &email); err != nil {
+		return fmt.Errorf("%w: unmarshal stored email: %w", errUndeliverable, err)
+	}
+
+	for _, attachment := range email.Attachments {
+		if attachment.Content != "" || attachment.URL != "" {
+			continue
+		}
+		contents, err := s.repo.FindAttachmentContents(notif.ID)
+		if err != nil {
+			return fmt.Errorf("find attachment contents: %w", err)
+		}
+		for _, content := range contents {
+			if content.Position < len(email.Attachments) {
+				email.Attachments[content.Position].Content = content.Content
+			}
+		}
+		break
+	}
+	return s.sendEmail(notif, email)
+}
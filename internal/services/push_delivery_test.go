@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"testing"
+)
+
+// deviceStore is a DeviceRepository over a slice, recording saves and updates
+type deviceStore struct {
+	repository.DeviceRepository
+	devices []models.Device
+	saved   int
+	updated []models.Device
+}
+
+func (r *deviceStore) FindByToken(token string) (*models.Device, error) {
+	for _, device := range r.devices {
+		if device.Token == token {
+			return &device, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *deviceStore) Save(device *models.Device) error {
+	r.saved++
+	return nil
+}
+
+func (r *deviceStore) Update(device *models.Device) error {
+	r.updated = append(r.updated, *device)
+	return nil
+}
+
+func (r *deviceStore) RemoveTopics(deviceID uint) error { return nil }
+
+type publishRecorder struct {
+	subjects []string
+}
+
+func (p *publishRecorder) Publish(subject string, data interface{}) error {
+	p.subjects = append(p.subjects, subject)
+	return nil
+}
+
+func TestInvalidateToken(t *testing.T) {
+	devices := &deviceStore{devices: []models.Device{{ID: 7, UserID: 42, Token: "registered", IsActive: true}}}
+	publisher := &publishRecorder{}
+	s := &notificationService{deviceRepo: devices, publisher: publisher}
+
+	s.invalidateToken(models.Device{Token: "never-registered"}, "unregistered")
+	if devices.saved != 0 || len(devices.updated) != 0 || len(publisher.subjects) != 0 {
+		t.Errorf("unknown token: saved %d, updated %v, published %v", devices.saved, devices.updated, publisher.subjects)
+	}
+
+	s.invalidateToken(models.Device{Token: "registered"}, "unregistered")
+	if len(devices.updated) != 1 || devices.updated[0].IsActive || devices.updated[0].InvalidatedAt == nil {
+		t.Errorf("registered token: updated %+v", devices.updated)
+	}
+	if len(publisher.subjects) != 1 {
+		t.Errorf("registered token: published %v", publisher.subjects)
+	}
+}
+
+func TestRecordPushResultPrunesInvalidToken(t *testing.T) {
+	devices := &deviceStore{devices: []models.Device{{ID: 7, UserID: 42, Token: "registered", IsActive: true}}}
+	repo := &updateRecorder{}
+	s := &notificationService{repo: repo, deviceRepo: devices}
+	notif := &models.Notification{ID: 1, Channel: models.ChannelPush, Status: "pending"}
+
+	sendErr := fmt.Errorf("FCM send: %w: %w", ErrInvalidToken, errors.New("requested entity was not found"))
+	s.recordPushResult(pushDelivery{device: models.Device{Token: "registered"}, notif: notif}, sendErr)
+	if len(repo.updated) != 1 || repo.updated[0].Status != "failed" {
+		t.Errorf("updated notifications = %+v", repo.updated)
+	}
+	if len(devices.updated) != 1 || devices.updated[0].IsActive {
+		t.Errorf("updated devices = %+v", devices.updated)
+	}
+}
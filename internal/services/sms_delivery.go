@@ -0,0 +1,133 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"notification-service/internal/models"
+	"notification-service/internal/utils"
+	"notification-service/internal/utils/sms"
+	"strings"
+	"time"
+)
+
+// SendNotificationSMS stores the SMS as a pending notification row before sending it, like emails
+func (s *notificationService) SendNotificationSMS(data []byte) error {
+	var message models.SMS
+	if err := json.Unmarshal(data, &message); err != nil {
+		return fmt.Errorf("unmarshal sms: %w", err)
+	}
+
+	notif := &models.Notification{
+		Channel:      models.ChannelSMS,
+		Recipient:    message.To,
+		TemplateName: message.Template,
+		EventType:    message.EventType,
+		Payload:      string(data),
+		Status:       "pending",
+		CreatedAt:    time.Now(),
+	}
+	if message.UserID != 0 {
+		userID := message.UserID
+		notif.UserID = &userID
+	}
+	if err := s.repo.Save(notif); err != nil {
+		return fmt.Errorf("save notification: %w", err)
+	}
+
+	return s.recordResult(notif, s.sendSMS(notif, message))
+}
+
+func (s *notificationService) sendSMS(notif *models.Notification, message models.SMS) error {
+	if s.sms == nil {
+		return fmt.Errorf("%w: no sms provider is configured", errUndeliverable)
+	}
+
+	to, err := s.smsRecipient(message)
+	if err != nil {
+		return err
+	}
+	notif.Recipient = to
+
+	body := message.Body
+	if body == "" {
+		if message.Template == "" {
+			return fmt.Errorf("%w: sms has neither a body nor a template", errUndeliverable)
+		}
+		rendered, err := s.templates.RenderSMS(message.Template, s.recipientLocale(message.Locale, message.UserID, ""), message.Variables)
+		if err != nil {
+			return fmt.Errorf("%w: render sms: %w", errUndeliverable, err)
+		}
+		body = rendered.Body
+	}
+	notif.Body = body
+
+	info := sms.Analyze(body)
+	if info.Segments > s.smsMaxSegments {
+		return fmt.Errorf("%w: sms needs %d %s segments, more than the %d allowed", errUndeliverable, info.Segments, info.Encoding, s.smsMaxSegments)
+	}
+
+	result, err := s.sms.Send(&sms.Message{To: to, Body: body})
+	if err != nil {
+		if errors.Is(err, sms.ErrRejected) {
+			return fmt.Errorf("%w: send sms: %w", errUndeliverable, err)
+		}
+		return fmt.Errorf("send sms: %w", err)
+	}
+	notif.ExternalID = result.MessageID
+
+	log.Printf("✅ SMS sent to %s in %d %s segment(s)", to, info.Segments, info.Encoding)
+	return nil
+}
+
+// smsRecipient validates the event's number, falling back to the phone number of the user profile
+func (s *notificationService) smsRecipient(message models.SMS) (string, error) {
+	to := message.To
+	if to == "" && message.UserID != 0 {
+		user, err := s.userRepo.FindByID(message.UserID)
+		if err != nil {
+			return "", fmt.Errorf("find user: %w", err)
+		}
+		if user != nil {
+			to = user.PhoneNumber
+		}
+	}
+	if to == "" {
+		return "", fmt.Errorf("%w: sms has no recipient", errUndeliverable)
+	}
+
+	to = normalizePhoneNumber(to)
+	if err := utils.ValidatePhoneNumber(to); err != nil {
+		return "", fmt.Errorf("%w: %w", errUndeliverable, err)
+	}
+	return to, nil
+}
+
+// retrySMS rebuilds the SMS from the event stored in the row's payload
+func (s *notificationService) retrySMS(notif *models.Notification) error {
+	var message models.SMS
+	if err := json.Unmarshal([]byte(notif.Payload), &message); err != nil {
+		return fmt.Errorf("%w: unmarshal stored sms: %w", errUndeliverable, err)
+	}
+	return s.sendSMS(notif, message)
+}
+
+// normalizePhoneNumber turns the local 08... and bare 62... forms into +62... and drops the
+// spaces, dashes and parentheses people type
+func normalizePhoneNumber(phone string) string {
+	phone = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" -().", r) {
+			return -1
+		}
+		return r
+	}, phone)
+
+	switch {
+	case strings.HasPrefix(phone, "0"):
+		return "+62" + phone[1:]
+	case strings.HasPrefix(phone, "62"):
+		return "+" + phone
+	}
+	return phone
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"notification-service/internal/models"
+	"strings"
+	"time"
+)
+
+// Receipt sources accepted by HandleReceipt
+const (
+	ReceiptTwilio = "twilio"
+	ReceiptJSON   = "json" // generic JSON for aggregators whose callbacks are configured to post it
+)
+
+// Receipt outcomes; intermediate states such as queued or sent leave the row as it is
+const (
+	receiptDelivered = "delivered"
+	receiptFailed    = "failed"
+)
+
+func parseReceipts(provider string, body []byte) ([]models.SMSReceipt, error) {
+	var receipts []models.SMSReceipt
+	var err error
+	switch provider {
+	case ReceiptTwilio:
+		receipts, err = parseTwilioReceipt(body)
+	case ReceiptJSON:
+		receipts, err = parseJSONReceipts(body)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProvider, provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidReceipt, err)
+	}
+
+	for i := range receipts {
+		if receipts[i].OccurredAt.IsZero() {
+			receipts[i].OccurredAt = time.Now()
+		}
+	}
+	return receipts, nil
+}
+
+// parseTwilioReceipt reads a status callback, a form with MessageSid, MessageStatus and ErrorCode
+func parseTwilioReceipt(body []byte) ([]models.SMSReceipt, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+	if form.Get("MessageSid") == "" || form.Get("MessageStatus") == "" {
+		return nil, fmt.Errorf("callback lacks MessageSid or MessageStatus")
+	}
+	return []models.SMSReceipt{{
+		MessageID: form.Get("MessageSid"),
+		Status:    form.Get("MessageStatus"),
+		ErrorCode: form.Get("ErrorCode"),
+		Error:     form.Get("ErrorMessage"),
+	}}, nil
+}
+
+type jsonReceipt struct {
+	MessageID string    `json:"message_id"`
+	Status    string    `json:"status"`
+	ErrorCode string    `json:"error_code"`
+	Error     string    `json:"error"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// parseJSONReceipts takes one receipt object or an array of them
+func parseJSONReceipts(body []byte) ([]models.SMSReceipt, error) {
+	var items []jsonReceipt
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var item jsonReceipt
+		if err := json.Unmarshal(trimmed, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	} else if err := json.Unmarshal(trimmed, &items); err != nil {
+		return nil, err
+	}
+
+	receipts := make([]models.SMSReceipt, 0, len(items))
+	for _, item := range items {
+		if item.MessageID == "" || item.Status == "" {
+			return nil, fmt.Errorf("receipt lacks message_id or status")
+		}
+		receipts = append(receipts, models.SMSReceipt{
+			MessageID:  item.MessageID,
+			Status:     item.Status,
+			ErrorCode:  item.ErrorCode,
+			Error:      item.Error,
+			OccurredAt: item.Timestamp,
+		})
+	}
+	return receipts, nil
+}
+
+// receiptOutcome maps provider statuses onto delivered or failed, or "" for intermediate states
+func receiptOutcome(status string) string {
+	switch strings.ToLower(status) {
+	case "delivered", "delivrd":
+		return receiptDelivered
+	case "failed", "undelivered", "undeliv", "rejected", "rejectd", "expired":
+		return receiptFailed
+	}
+	return ""
+}
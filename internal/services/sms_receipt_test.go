@@ -0,0 +1,85 @@
+package services
+
+import (
+	"errors"
+	"notification-service/internal/models"
+	"testing"
+	"time"
+)
+
+func TestParseTwilioReceipt(t *testing.T) {
+	receipts, err := parseTwilioReceipt([]byte("MessageSid=SM123&MessageStatus=undelivered&ErrorCode=30003&ErrorMessage=Unreachable+handset&To=%2B6281234567890"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := models.SMSReceipt{MessageID: "SM123", Status: "undelivered", ErrorCode: "30003", Error: "Unreachable handset"}
+	if len(receipts) != 1 || receipts[0] != want {
+		t.Errorf("receipts = %+v, want %+v", receipts, want)
+	}
+
+	for _, body := range []string{"MessageStatus=delivered", "MessageSid=SM123", "%zz"} {
+		if _, err := parseTwilioReceipt([]byte(body)); err == nil {
+			t.Errorf("parsed %q", body)
+		}
+	}
+}
+
+func TestParseJSONReceipts(t *testing.T) {
+	at := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	one, err := parseJSONReceipts([]byte(` {"message_id":"987","status":"DELIVRD","timestamp":"2026-10-19T10:00:00Z"}`))
+	if err != nil {
+		t.Fatalf("parse object: %v", err)
+	}
+	if want := (models.SMSReceipt{MessageID: "987", Status: "DELIVRD", OccurredAt: at}); len(one) != 1 || one[0] != want {
+		t.Errorf("receipts = %+v, want %+v", one, want)
+	}
+
+	many, err := parseJSONReceipts([]byte(`[{"message_id":"1","status":"delivered"},{"message_id":"2","status":"failed","error_code":"34","error":"absent subscriber"}]`))
+	if err != nil {
+		t.Fatalf("parse array: %v", err)
+	}
+	if len(many) != 2 || many[1] != (models.SMSReceipt{MessageID: "2", Status: "failed", ErrorCode: "34", Error: "absent subscriber"}) {
+		t.Errorf("receipts = %+v", many)
+	}
+
+	for _, body := range []string{`{"status":"delivered"}`, `[{"message_id":"1"}]`, `{"message_id":`, `"delivered"`} {
+		if _, err := parseJSONReceipts([]byte(body)); err == nil {
+			t.Errorf("parsed %s", body)
+		}
+	}
+}
+
+func TestParseReceipts(t *testing.T) {
+	receipts, err := parseReceipts(ReceiptJSON, []byte(`{"message_id":"1","status":"delivered"}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if receipts[0].OccurredAt.IsZero() {
+		t.Error("receipt without a timestamp was not given one")
+	}
+
+	if _, err := parseReceipts("nexmo", nil); !errors.Is(err, ErrUnsupportedProvider) {
+		t.Errorf("unknown provider error = %v", err)
+	}
+	if _, err := parseReceipts(ReceiptTwilio, []byte("MessageSid=SM123")); !errors.Is(err, ErrInvalidReceipt) {
+		t.Errorf("invalid receipt error = %v", err)
+	}
+}
+
+func TestReceiptOutcome(t *testing.T) {
+	tests := map[string]string{
+		"delivered":   receiptDelivered,
+		"DELIVRD":     receiptDelivered,
+		"undelivered": receiptFailed,
+		"REJECTD":     receiptFailed,
+		"expired":     receiptFailed,
+		"queued":      "",
+		"sent":        "",
+	}
+	for status, want := range tests {
+		if got := receiptOutcome(status); got != want {
+			t.Errorf("receiptOutcome(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"strings"
+)
+
+var ErrInvalidReceipt = errors.New("invalid delivery receipt")
+
+type SMSService interface {
+	AuthorizeWebhook(token string) error
+	HandleReceipt(provider, token string, body []byte) (int, error)
+}
+
+type smsService struct {
+	repo          repository.NotificationRepository
+	webhookSecret string
+}
+
+func NewSMSService(repo repository.NotificationRepository, webhookSecret string) SMSService {
+	return &smsService{repo: repo, webhookSecret: webhookSecret}
+}
+
+// AuthorizeWebhook checks the shared token, so callers can refuse a request before reading its body
+func (s *smsService) AuthorizeWebhook(token string) error {
+	return checkWebhookToken(s.webhookSecret, token)
+}
+
+// HandleReceipt applies delivery receipts to the SMS rows they refer to and returns how many
+// rows changed. Receipts for unknown messages are skipped; they can arrive before the send
+// recorded the provider's ID, and the final receipt follows later.
+func (s *smsService) HandleReceipt(provider, token string, body []byte) (int, error) {
+	if err := s.AuthorizeWebhook(token); err != nil {
+		return 0, err
+	}
+
+	receipts, err := parseReceipts(provider, body)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	var errs []error
+	for _, receipt := range receipts {
+		outcome := receiptOutcome(receipt.Status)
+		if outcome == "" {
+			continue
+		}
+
+		notif, err := s.repo.FindByExternalID(models.ChannelSMS, receipt.MessageID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("find notification: %w", err))
+			continue
+		}
+		if notif == nil {
+			log.Printf("⚠️ Delivery receipt for unknown sms %s", receipt.MessageID)
+			continue
+		}
+
+		if outcome == receiptDelivered {
+			notif.Status = "delivered"
+			notif.DeliveredAt = &receipt.OccurredAt
+		} else {
+			notif.Status = "failed"
+			reason := strings.TrimSpace(fmt.Sprintf("%s %s %s", receipt.Status, receipt.ErrorCode, receipt.Error))
+			notif.LastError = &reason
+		}
+		if err := s.repo.Update(notif); err != nil {
+			errs = append(errs, fmt.Errorf("update notification %d: %w", notif.ID, err))
+			continue
+		}
+		updated++
+	}
+	return updated, errors.Join(errs...)
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"strings"
+)
+
+var (
+	ErrSuppressionNotFound  = errors.New("address is not suppressed")
+	ErrWebhookUnauthorized  = errors.New("invalid webhook token")
+	ErrUnsupportedProvider  = errors.New("unsupported feedback provider")
+	ErrInvalidFeedbackEvent = errors.New("invalid feedback payload")
+)
+
+type SuppressionService interface {
+	AuthorizeWebhook(token string) error
+	HandleFeedback(provider, token string, body []byte) (int, error)
+	GetSuppressions(search string) ([]models.EmailSuppression, error)
+	GetSuppression(email string) (*models.SuppressionDetail, error)
+	AddSuppression(request *models.SuppressionRequest) (*models.EmailSuppression, error)
+	RemoveSuppression(email string) error
+}
+
+type suppressionService struct {
+	repo          repository.SuppressionRepository
+	webhookSecret string
+}
+
+func NewSuppressionService(repo repository.SuppressionRepository, webhookSecret string) SuppressionService {
+	return &suppressionService{repo: repo, webhookSecret: webhookSecret}
+}
+
+// AuthorizeWebhook checks the shared token, so callers can refuse a request before reading its body
+func (s *suppressionService) AuthorizeWebhook(token string) error {
+	return checkWebhookToken(s.webhookSecret, token)
+}
+
+// checkWebhookToken compares in constant time; an unset secret refuses every request
+func checkWebhookToken(secret, token string) error {
+	if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return ErrWebhookUnauthorized
+	}
+	return nil
+}
+
+// HandleFeedback records the bounces and complaints in a provider webhook or DSN and suppresses
+// hard-bounced and complaining addresses. It returns how many feedback records were stored.
+func (s *suppressionService) HandleFeedback(provider, token string, body []byte) (int, error) {
+	if err := s.AuthorizeWebhook(token); err != nil {
+		return 0, err
+	}
+
+	feedback, err := parseFeedback(provider, body)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for i := range feedback {
+		item := &feedback[i]
+		item.Recipient = normalizeEmail(item.Recipient)
+		if item.Recipient == "" {
+			continue
+		}
+		if err := s.repo.SaveFeedback(item); err != nil {
+			errs = append(errs, fmt.Errorf("save feedback: %w", err))
+			continue
+		}
+
+		reason := ""
+		switch {
+		case item.Type == models.FeedbackComplaint:
+			reason = models.SuppressionComplaint
+		case item.BounceType == models.BounceHard:
+			reason = models.SuppressionHardBounce
+		default:
+			continue
+		}
+		suppression := &models.EmailSuppression{Email: item.Recipient, Reason: reason, Detail: item.Reason}
+		if err := s.repo.Suppress(suppression); err != nil {
+			errs = append(errs, fmt.Errorf("suppress %s: %w", item.Recipient, err))
+			continue
+		}
+		log.Printf("⛔ Suppressed %s after %s", item.Recipient, reason)
+	}
+	return len(feedback), errors.Join(errs...)
+}
+
+func (s *suppressionService) GetSuppressions(search string) ([]models.EmailSuppression, error) {
+	suppressions, err := s.repo.FindSuppressions(normalizeEmail(search))
+	if err != nil {
+		return nil, fmt.Errorf("find suppressions: %w", err)
+	}
+	return suppressions, nil
+}
+
+func (s *suppressionService) GetSuppression(email string) (*models.SuppressionDetail, error) {
+	email = normalizeEmail(email)
+	suppression, err := s.repo.FindSuppression(email)
+	if err != nil {
+		return nil, fmt.Errorf("find suppression: %w", err)
+	}
+	if suppression == nil {
+		return nil, ErrSuppressionNotFound
+	}
+
+	feedback, err := s.repo.FindFeedback(email)
+	if err != nil {
+		return nil, fmt.Errorf("find feedback: %w", err)
+	}
+	return &models.SuppressionDetail{Suppression: suppression, Feedback: feedback}, nil
+}
+
+func (s *suppressionService) AddSuppression(request *models.SuppressionRequest) (*models.EmailSuppression, error) {
+	email := normalizeEmail(request.Email)
+	if err := s.repo.Suppress(&models.EmailSuppression{Email: email, Reason: models.SuppressionManual, Detail: request.Detail}); err != nil {
+		return nil, fmt.Errorf("suppress: %w", err)
+	}
+
+	// An existing suppression keeps its original reason, so return what is stored
+	suppression, err := s.repo.FindSuppression(email)
+	if err != nil {
+		return nil, fmt.Errorf("find suppression: %w", err)
+	}
+	return suppression, nil
+}
+
+func (s *suppressionService) RemoveSuppression(email string) error {
+	removed, err := s.repo.Unsuppress(normalizeEmail(email))
+	if err != nil {
+		return fmt.Errorf("unsuppress: %w", err)
+	}
+	if !removed {
+		return ErrSuppressionNotFound
+	}
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
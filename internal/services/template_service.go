@@ -0,0 +1,243 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"notification-service/internal/templates"
+)
+
+var (
+	ErrTemplateExists       = errors.New("template already exists")
+	ErrTemplateNotFound     = errors.New("template not found")
+	ErrVersionNotFound      = errors.New("template version not found")
+	ErrVersionNotDraft      = errors.New("only draft versions can be changed")
+	ErrVersionPublished     = errors.New("version is already published")
+	ErrInvalidTemplate      = errors.New("template does not compile")
+	ErrTemplateVersionEmpty = errors.New("template has no versions")
+)
+
+type TemplateService interface {
+	CreateTemplate(request *models.TemplateRequest) (*models.Template, error)
+	GetTemplates(channel string) ([]models.Template, error)
+	GetTemplate(id uint) (*models.Template, error)
+	UpdateTemplate(id uint, request *models.UpdateTemplateRequest) (*models.Template, error)
+	DeleteTemplate(id uint) error
+	GetVersions(id uint) ([]models.TemplateVersion, error)
+	CreateVersion(id uint, request *models.TemplateVersionRequest, createdBy string) (*models.TemplateVersion, error)
+	UpdateVersion(id uint, version int, request *models.TemplateVersionRequest) (*models.TemplateVersion, error)
+	PublishVersion(id uint, version int) (*models.TemplateVersion, error)
+	Preview(id uint, request *models.TemplatePreviewRequest) (*models.TemplatePreview, error)
+}
+
+type templateService struct {
+	repo     repository.TemplateRepository
+	registry templates.Registry
+}
+
+func NewTemplateService(repo repository.TemplateRepository, registry templates.Registry) TemplateService {
+	return &templateService{repo: repo, registry: registry}
+}
+
+func (s *templateService) CreateTemplate(request *models.TemplateRequest) (*models.Template, error) {
+	locale := templates.NormalizeLocale(request.Locale)
+	if locale == "" {
+		locale = templates.DefaultLocale
+	}
+
+	existing, err := s.repo.FindByName(request.Channel, request.Name, locale)
+	if err != nil {
+		return nil, fmt.Errorf("find template: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrTemplateExists
+	}
+
+	template := &models.Template{
+		Name:        request.Name,
+		Channel:     request.Channel,
+		Locale:      locale,
+		Description: request.Description,
+	}
+	if err := s.repo.Save(template); err != nil {
+		return nil, fmt.Errorf("save template: %w", err)
+	}
+	return template, nil
+}
+
+func (s *templateService) GetTemplates(channel string) ([]models.Template, error) {
+	list, err := s.repo.FindAll(channel)
+	if err != nil {
+		return nil, fmt.Errorf("find templates: %w", err)
+	}
+	return list, nil
+}
+
+func (s *templateService) GetTemplate(id uint) (*models.Template, error) {
+	template, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("find template: %w", err)
+	}
+	if template == nil {
+		return nil, ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+func (s *templateService) UpdateTemplate(id uint, request *models.UpdateTemplateRequest) (*models.Template, error) {
+	template, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Description = request.Description
+	if err := s.repo.Update(template); err != nil {
+		return nil, fmt.Errorf("update template: %w", err)
+	}
+	return template, nil
+}
+
+func (s *templateService) DeleteTemplate(id uint) error {
+	if _, err := s.GetTemplate(id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return fmt.Errorf("delete template: %w", err)
+	}
+	return nil
+}
+
+func (s *templateService) GetVersions(id uint) ([]models.TemplateVersion, error) {
+	if _, err := s.GetTemplate(id); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.repo.FindVersions(id)
+	if err != nil {
+		return nil, fmt.Errorf("find versions: %w", err)
+	}
+	return versions, nil
+}
+
+// CreateVersion stores a new draft; drafts may be incomplete and are only compiled on publish
+func (s *templateService) CreateVersion(id uint, request *models.TemplateVersionRequest, createdBy string) (*models.TemplateVersion, error) {
+	if _, err := s.GetTemplate(id); err != nil {
+		return nil, err
+	}
+
+	version := &models.TemplateVersion{
+		TemplateID: id,
+		Status:     "draft",
+		CreatedBy:  createdBy,
+	}
+	applyVersionRequest(version, request)
+	if err := s.repo.CreateVersion(version); err != nil {
+		return nil, fmt.Errorf("save version: %w", err)
+	}
+	return version, nil
+}
+
+func (s *templateService) UpdateVersion(id uint, version int, request *models.TemplateVersionRequest) (*models.TemplateVersion, error) {
+	v, err := s.findVersion(id, version)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status != "draft" {
+		return nil, ErrVersionNotDraft
+	}
+
+	applyVersionRequest(v, request)
+	if err := s.repo.UpdateVersion(v); err != nil {
+		return nil, fmt.Errorf("update version: %w", err)
+	}
+	return v, nil
+}
+
+// PublishVersion validates a draft or archived version for its channel and replaces the published
+// version with it; publishing an archived version rolls back to it
+func (s *templateService) PublishVersion(id uint, version int) (*models.TemplateVersion, error) {
+	template, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := s.findVersion(id, version)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status == "published" {
+		return nil, ErrVersionPublished
+	}
+
+	if err := s.registry.Validate(*template, *v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	if err := s.repo.PublishVersion(id, version); err != nil {
+		return nil, fmt.Errorf("publish version: %w", err)
+	}
+	return s.findVersion(id, version)
+}
+
+// Preview renders the requested version, or the latest one, with sample variables
+func (s *templateService) Preview(id uint, request *models.TemplatePreviewRequest) (*models.TemplatePreview, error) {
+	template, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var v *models.TemplateVersion
+	if request.Version > 0 {
+		v, err = s.findVersion(id, request.Version)
+	} else {
+		v, err = s.repo.FindLatestVersion(id)
+		if err == nil && v == nil {
+			err = ErrTemplateVersionEmpty
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	variables := request.Variables
+	if len(variables) == 0 {
+		variables = json.RawMessage("{}")
+	}
+
+	rendered, err := s.registry.RenderVersion(*template, *v, variables)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	return &models.TemplatePreview{
+		Template: rendered.Template,
+		Version:  rendered.Version,
+		Locale:   rendered.Locale,
+		Subject:  rendered.Subject,
+		HTML:     rendered.HTML,
+		Text:     rendered.Text,
+		Title:    rendered.Title,
+		Body:     rendered.Body,
+	}, nil
+}
+
+func (s *templateService) findVersion(id uint, version int) (*models.TemplateVersion, error) {
+	v, err := s.repo.FindVersion(id, version)
+	if err != nil {
+		return nil, fmt.Errorf("find version: %w", err)
+	}
+	if v == nil {
+		return nil, ErrVersionNotFound
+	}
+	return v, nil
+}
+
+func applyVersionRequest(version *models.TemplateVersion, request *models.TemplateVersionRequest) {
+	version.Subject = request.Subject
+	version.HTML = request.HTML
+	version.Text = request.Text
+	version.Title = request.Title
+	version.Body = request.Body
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"notification-service/internal/utils"
+	"notification-service/internal/utils/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidTrackingToken = errors.New("invalid tracking token")
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// scannerGrace is how soon after sending a hit is attributed to a mail gateway scanning the
+// message rather than to the recipient
+const scannerGrace = 2 * time.Second
+
+// botUserAgents are user agent fragments of crawlers, link scanners and HTTP libraries
+var botUserAgents = []string{
+	"bot", "crawler", "spider", "slurp", "scanner", "preview", "curl", "wget", "python-requests",
+	"go-http-client", "headlesschrome", "barracuda", "mimecast", "proofpoint", "urldefense", "safelinks",
+}
+
+type TrackingService interface {
+	Enabled() bool
+	Instrument(notificationID uint, body string) string
+	RecordOpen(token, ip, userAgent string) error
+	RecordClick(token, ip, userAgent string) (string, error)
+	GetTracking(notificationID uint) (*models.TrackingSummary, error)
+}
+
+type trackingService struct {
+	repo          repository.TrackingRepository
+	notifications repository.NotificationRepository
+	redis         utils.RedisService
+	baseURL       string
+	secret        []byte
+	dedupeWindow  time.Duration
+}
+
+// NewTrackingService signs tracking links with secret and points them at baseURL, the public
+// address of this service; tracking stays off while either is empty
+func NewTrackingService(repo repository.TrackingRepository, notifications repository.NotificationRepository, redis utils.RedisService, baseURL, secret string, dedupeWindow time.Duration) TrackingService {
+	return &trackingService{
+		repo:          repo,
+		notifications: notifications,
+		redis:         redis,
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		secret:        []byte(secret),
+		dedupeWindow:  dedupeWindow,
+	}
+}
+
+func (s *trackingService) Enabled() bool {
+	return s.baseURL != "" && len(s.secret) > 0
+}
+
+// Instrument routes the http(s) links of an email body through the click endpoint and adds the
+// open pixel, all signed for the notification row
+func (s *trackingService) Instrument(notificationID uint, body string) string {
+	id := strconv.FormatUint(uint64(notificationID), 10)
+	body = mail.RewriteLinks(body, func(href string) string {
+		return s.baseURL + "/t/c/" + s.sign(models.TrackingClick+":"+id+":"+href)
+	})
+	return mail.AppendPixel(body, s.baseURL+"/t/o/"+s.sign(models.TrackingOpen+":"+id)+".gif")
+}
+
+// RecordOpen records the open behind a pixel token; the pixel is served whatever the outcome
+func (s *trackingService) RecordOpen(token, ip, userAgent string) error {
+	notificationID, _, err := s.verify(strings.TrimSuffix(token, ".gif"), models.TrackingOpen)
+	if err != nil {
+		return err
+	}
+	return s.record(&models.EmailTrackingEvent{NotificationID: notificationID, Type: models.TrackingOpen, IP: ip, UserAgent: userAgent})
+}
+
+// RecordClick records the click behind a link token and returns the URL to redirect to. Recording
+// failures are logged rather than returned so the recipient still reaches the link.
+func (s *trackingService) RecordClick(token, ip, userAgent string) (string, error) {
+	notificationID, target, err := s.verify(token, models.TrackingClick)
+	if err != nil {
+		return "", err
+	}
+	event := &models.EmailTrackingEvent{NotificationID: notificationID, Type: models.TrackingClick, URL: target, IP: ip, UserAgent: userAgent}
+	if err := s.record(event); err != nil {
+		log.Printf("⚠️ Recording click on notification %d: %v", notificationID, err)
+	}
+	return target, nil
+}
+
+func (s *trackingService) GetTracking(notificationID uint) (*models.TrackingSummary, error) {
+	notif, err := s.notifications.FindByID(notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("find notification: %w", err)
+	}
+	if notif == nil || notif.Channel != models.ChannelEmail {
+		return nil, ErrNotificationNotFound
+	}
+
+	events, err := s.repo.FindEvents(notificationID)
+	if err != nil {
+		return nil, fmt.Errorf("find tracking events: %w", err)
+	}
+
+	summary := &models.TrackingSummary{NotificationID: notificationID, Events: events}
+	for _, event := range events {
+		switch event.Type {
+		case models.TrackingOpen:
+			summary.Opens++
+		case models.TrackingClick:
+			summary.Clicks++
+		}
+	}
+	return summary, nil
+}
+
+// record drops hits from bots and repeats of the same open or click within the dedupe window
+func (s *trackingService) record(event *models.EmailTrackingEvent) error {
+	if isBot(event.UserAgent) {
+		return nil
+	}
+
+	notif, err := s.notifications.FindByID(event.NotificationID)
+	if err != nil {
+		return fmt.Errorf("find notification: %w", err)
+	}
+	if notif == nil {
+		return ErrNotificationNotFound
+	}
+	if notif.SentAt == nil || time.Since(*notif.SentAt) < scannerGrace {
+		return nil
+	}
+
+	key := fmt.Sprintf("tracking:%s:%d", event.Type, event.NotificationID)
+	if event.URL != "" {
+		sum := sha256.Sum256([]byte(event.URL))
+		key += ":" + hex.EncodeToString(sum[:8])
+	}
+	first, err := s.redis.AcquireLock(key, event.IP, s.dedupeWindow)
+	if err != nil {
+		log.Printf("⚠️ Tracking dedupe unavailable, recording anyway: %v", err)
+	} else if !first {
+		return nil
+	}
+
+	if err := s.repo.SaveEvent(event); err != nil {
+		return fmt.Errorf("save tracking event: %w", err)
+	}
+	return nil
+}
+
+// sign encodes payload as base64url followed by a truncated HMAC-SHA256 of it
+func (s *trackingService) sign(payload string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+// verify checks a token's signature and type and returns its notification ID and click URL
+func (s *trackingService) verify(token, kind string) (uint, string, error) {
+	if !s.Enabled() {
+		return 0, "", ErrInvalidTrackingToken
+	}
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, "", ErrInvalidTrackingToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, "", ErrInvalidTrackingToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.mac(string(payload))) {
+		return 0, "", ErrInvalidTrackingToken
+	}
+
+	parts := strings.SplitN(string(payload), ":", 3)
+	if parts[0] != kind || len(parts) < 2 || (kind == models.TrackingClick) != (len(parts) == 3) {
+		return 0, "", ErrInvalidTrackingToken
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, "", ErrInvalidTrackingToken
+	}
+	if kind == models.TrackingClick {
+		return uint(id), parts[2], nil
+	}
+	return uint(id), "", nil
+}
+
+func (s *trackingService) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)[:16]
+}
+
+func isBot(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	if userAgent == "" {
+		return true
+	}
+	for _, fragment := range botUserAgents {
+		if strings.Contains(userAgent, fragment) {
+			return true
+		}
+	}
+	return false
+}
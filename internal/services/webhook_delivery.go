@@ -0,0 +1,227 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"notification-service/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of every webhook call; the signature covers "<timestamp>.<body>" so receivers can
+// reject replays by checking the timestamp
+const (
+	HeaderWebhookID        = "X-Webhook-Id"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// maxAttemptResponse is how much of a subscriber's response body an attempt keeps
+const maxAttemptResponse = 1024
+
+// maxConcurrentWebhooks is the number of workers making webhook calls, which bounds the calls
+// in flight across all events so slow subscribers cannot pile up connections
+const maxConcurrentWebhooks = 8
+
+type webhookJob struct {
+	notif        *models.Notification
+	subscription *models.WebhookSubscription
+}
+
+// webhookQueueSize keeps the queue short enough to drain within half of retryLease even when
+// every call runs into the timeout, so the sweeper never claims a row that is still queued
+func webhookQueueSize(timeout time.Duration) int {
+	if timeout <= 0 {
+		return 0
+	}
+	return maxConcurrentWebhooks * int(retryLease/(2*timeout))
+}
+
+// SendNotificationWebhook stores one pending row per subscription whose filters match the event
+// and queues it for the webhook workers. Rows are saved leased, so the sweeper leaves them to the
+// workers; rows that find the queue full, or are still queued when the process stops, are sent by
+// the sweeper instead, like failed calls.
+func (s *notificationService) SendNotificationWebhook(data []byte) error {
+	var event models.WebhookEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return fmt.Errorf("unmarshal webhook event: %w", err)
+	}
+	if event.EventType == "" {
+		return errors.New("webhook event has no event_type")
+	}
+
+	subscriptions, err := s.webhooks.FindActive()
+	if err != nil {
+		return fmt.Errorf("find webhook subscriptions: %w", err)
+	}
+
+	var errs []error
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if !matchesEvent(subscription.Events, event.EventType) {
+			continue
+		}
+
+		subscriptionID := subscription.ID
+		lease := time.Now().Add(retryLease)
+		notif := &models.Notification{
+			Channel:        models.ChannelWebhook,
+			Recipient:      subscription.URL,
+			SubscriptionID: &subscriptionID,
+			EventType:      event.EventType,
+			Payload:        string(data),
+			Status:         "pending",
+			NextRetryAt:    &lease,
+			CreatedAt:      time.Now(),
+		}
+		if event.UserID != 0 {
+			userID := event.UserID
+			notif.UserID = &userID
+		}
+		if err := s.repo.Save(notif); err != nil {
+			errs = append(errs, fmt.Errorf("save notification: %w", err))
+			continue
+		}
+
+		select {
+		case s.webhookJobs <- webhookJob{notif: notif, subscription: subscription}:
+		default:
+			// Releasing the lease hands the row to the next sweep
+			now := time.Now()
+			notif.NextRetryAt = &now
+			if err := s.repo.Update(notif); err != nil {
+				errs = append(errs, fmt.Errorf("release webhook notification %d: %w", notif.ID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runWebhookWorker makes the queued calls one at a time and records their outcome
+func (s *notificationService) runWebhookWorker() {
+	for job := range s.webhookJobs {
+		if err := s.recordResult(job.notif, s.sendWebhook(job.notif, job.subscription)); err != nil {
+			log.Printf("⚠️ Webhook delivery %d failed: %v", job.notif.ID, err)
+		}
+	}
+}
+
+// sendWebhook makes one signed call and records it as an attempt. Any non-2xx answer is retried,
+// except 410 Gone, which deactivates the subscription.
+func (s *notificationService) sendWebhook(notif *models.Notification, subscription *models.WebhookSubscription) error {
+	body, err := json.Marshal(models.WebhookPayload{
+		ID:        notif.ID,
+		EventType: notif.EventType,
+		CreatedAt: notif.CreatedAt,
+		Data:      json.RawMessage(notif.Payload),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: marshal webhook payload: %w", errUndeliverable, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: build webhook request: %w", errUndeliverable, err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "notification-service-webhooks")
+	req.Header.Set(HeaderWebhookID, strconv.FormatUint(uint64(notif.ID), 10))
+	req.Header.Set(HeaderWebhookEvent, notif.EventType)
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(HeaderWebhookSignature, SignWebhook(subscription.Secret, timestamp, body))
+
+	attempt := &models.WebhookAttempt{
+		NotificationID: notif.ID,
+		SubscriptionID: subscription.ID,
+		Attempt:        notif.RetryCount + 1,
+	}
+	start := time.Now()
+	resp, sendErr := s.webhookClient.Do(req)
+	attempt.LatencyMs = time.Since(start).Milliseconds()
+	if sendErr == nil {
+		attempt.StatusCode = resp.StatusCode
+		response, _ := io.ReadAll(io.LimitReader(resp.Body, maxAttemptResponse))
+		attempt.Response = strings.ToValidUTF8(string(response), "")
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		case resp.StatusCode == http.StatusGone:
+			sendErr = fmt.Errorf("%w: subscriber responded %s", errUndeliverable, resp.Status)
+			s.deactivateSubscription(subscription)
+		default:
+			sendErr = fmt.Errorf("subscriber responded %s", resp.Status)
+		}
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+	if err := s.webhooks.SaveAttempt(attempt); err != nil {
+		log.Printf("⚠️ Failed to record webhook attempt for notification %d: %v", notif.ID, err)
+	}
+
+	if sendErr != nil {
+		return fmt.Errorf("post webhook: %w", sendErr)
+	}
+	log.Printf("✅ Webhook %s delivered to subscription %d in %dms", notif.EventType, subscription.ID, attempt.LatencyMs)
+	return nil
+}
+
+// retryWebhook resends to the row's subscription unless it was removed or deactivated meanwhile
+func (s *notificationService) retryWebhook(notif *models.Notification) error {
+	if notif.SubscriptionID == nil {
+		return fmt.Errorf("%w: webhook row has no subscription", errUndeliverable)
+	}
+	subscription, err := s.webhooks.FindByID(*notif.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("find webhook subscription: %w", err)
+	}
+	if subscription == nil || !subscription.Active {
+		return fmt.Errorf("%w: webhook subscription %d is gone or inactive", errUndeliverable, *notif.SubscriptionID)
+	}
+	return s.sendWebhook(notif, subscription)
+}
+
+func (s *notificationService) deactivateSubscription(subscription *models.WebhookSubscription) {
+	subscription.Active = false
+	if err := s.webhooks.Update(subscription); err != nil {
+		log.Printf("⚠️ Failed to deactivate webhook subscription %d: %v", subscription.ID, err)
+		return
+	}
+	log.Printf("🧹 Webhook subscription %d deactivated after 410 Gone", subscription.ID)
+}
+
+// SignWebhook returns the X-Webhook-Signature value, "sha256=" and the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the subscription secret
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// matchesEvent reports whether any pattern selects the event type: "*", a "prefix*" or an exact name
+func matchesEvent(patterns []string, eventType string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(eventType, prefix) {
+				return true
+			}
+		} else if pattern == eventType {
+			return true
+		}
+	}
+	return false
+}
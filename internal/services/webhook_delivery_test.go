@@ -0,0 +1,77 @@
+package services
+
+import (
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"testing"
+	"time"
+)
+
+// saveRecorder is a NotificationRepository that only accepts Save and Update
+type saveRecorder struct {
+	updateRecorder
+	saved []models.Notification
+}
+
+func (r *saveRecorder) Save(notification *models.Notification) error {
+	notification.ID = uint(len(r.saved) + 1)
+	r.saved = append(r.saved, *notification)
+	return nil
+}
+
+type activeSubscriptions struct {
+	repository.WebhookRepository
+	subscriptions []models.WebhookSubscription
+}
+
+func (r *activeSubscriptions) FindActive() ([]models.WebhookSubscription, error) {
+	return r.subscriptions, nil
+}
+
+func TestSendNotificationWebhookQueuesLeasedRows(t *testing.T) {
+	repo := &saveRecorder{}
+	s := &notificationService{
+		repo: repo,
+		webhooks: &activeSubscriptions{subscriptions: []models.WebhookSubscription{
+			{ID: 1, URL: "https://a.example.com/hook", Events: []string{"asset_*"}, Active: true},
+			{ID: 2, URL: "https://b.example.com/hook", Events: []string{"*"}, Active: true},
+			{ID: 3, URL: "https://c.example.com/hook", Events: []string{"user_created"}, Active: true},
+		}},
+		// Room for one job and no workers, so the second row finds the queue full
+		webhookJobs: make(chan webhookJob, 1),
+	}
+
+	start := time.Now()
+	if err := s.SendNotificationWebhook([]byte(`{"event_type":"asset_moved"}`)); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if len(repo.saved) != 2 {
+		t.Fatalf("saved %d rows, want 2", len(repo.saved))
+	}
+	for _, notif := range repo.saved {
+		if notif.NextRetryAt == nil || notif.NextRetryAt.Before(start.Add(retryLease)) {
+			t.Errorf("row %d saved with next_retry_at %v, want the lease", notif.ID, notif.NextRetryAt)
+		}
+	}
+
+	queued := <-s.webhookJobs
+	if queued.notif.ID != 1 || queued.subscription.ID != 1 {
+		t.Errorf("queued row %d for subscription %d", queued.notif.ID, queued.subscription.ID)
+	}
+	if len(repo.updated) != 1 || repo.updated[0].ID != 2 || repo.updated[0].NextRetryAt.After(time.Now()) {
+		t.Errorf("released = %+v, want row 2 due now", repo.updated)
+	}
+}
+
+func TestWebhookQueueSize(t *testing.T) {
+	for _, timeout := range []time.Duration{time.Second, 10 * time.Second, time.Minute, 10 * time.Minute} {
+		size := webhookQueueSize(timeout)
+		if drain := time.Duration(size/maxConcurrentWebhooks+1) * timeout; size > 0 && drain > retryLease {
+			t.Errorf("timeout %s: queue of %d takes %s to drain, longer than the lease", timeout, size, drain)
+		}
+	}
+	if webhookQueueSize(10*time.Second) == 0 {
+		t.Error("default timeout leaves no queue")
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"notification-service/internal/utils"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrInvalidWebhookURL    = errors.New("webhook url must be http or https")
+	ErrPrivateWebhookURL    = errors.New("webhook url must resolve to public addresses")
+)
+
+// defaultAttemptLimit bounds the attempt history returned for a subscription
+const defaultAttemptLimit = 100
+
+type WebhookService interface {
+	CreateSubscription(request *models.WebhookSubscriptionRequest, createdBy string) (*models.WebhookSubscriptionCreated, error)
+	GetSubscriptions() ([]models.WebhookSubscription, error)
+	GetSubscription(id uint) (*models.WebhookSubscription, error)
+	UpdateSubscription(id uint, request *models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	DeleteSubscription(id uint) error
+	GetAttempts(id uint) ([]models.WebhookAttempt, error)
+}
+
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: repo}
+}
+
+// CreateSubscription generates the signing secret, which the response carries only this once
+func (s *webhookService) CreateSubscription(request *models.WebhookSubscriptionRequest, createdBy string) (*models.WebhookSubscriptionCreated, error) {
+	if err := validateWebhookURL(request.URL); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generate webhook secret: %w", err)
+	}
+
+	subscription := &models.WebhookSubscription{
+		Name:      request.Name,
+		URL:       request.URL,
+		Secret:    hex.EncodeToString(secret),
+		Events:    request.Events,
+		Active:    request.Active == nil || *request.Active,
+		CreatedBy: createdBy,
+	}
+	if err := s.repo.Save(subscription); err != nil {
+		return nil, fmt.Errorf("save webhook subscription: %w", err)
+	}
+	return &models.WebhookSubscriptionCreated{WebhookSubscription: *subscription, Secret: subscription.Secret}, nil
+}
+
+func (s *webhookService) GetSubscriptions() ([]models.WebhookSubscription, error) {
+	subscriptions, err := s.repo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("find webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (s *webhookService) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	subscription, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("find webhook subscription: %w", err)
+	}
+	if subscription == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+// UpdateSubscription replaces the name, URL and filters, keeping the secret
+func (s *webhookService) UpdateSubscription(id uint, request *models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	if err := validateWebhookURL(request.URL); err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Name = request.Name
+	subscription.URL = request.URL
+	subscription.Events = request.Events
+	if request.Active != nil {
+		subscription.Active = *request.Active
+	}
+	if err := s.repo.Update(subscription); err != nil {
+		return nil, fmt.Errorf("update webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (s *webhookService) DeleteSubscription(id uint) error {
+	if _, err := s.GetSubscription(id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return fmt.Errorf("delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (s *webhookService) GetAttempts(id uint) ([]models.WebhookAttempt, error) {
+	if _, err := s.GetSubscription(id); err != nil {
+		return nil, err
+	}
+	attempts, err := s.repo.FindAttempts(id, defaultAttemptLimit)
+	if err != nil {
+		return nil, fmt.Errorf("find webhook attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+func validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+
+	// Checked again on every call by the delivery client, since DNS can change afterwards
+	ips, err := net.LookupIP(parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPrivateWebhookURL, err)
+	}
+	for _, ip := range ips {
+		if !utils.IsPublicIP(ip) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateWebhookURL, parsed.Hostname(), ip)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := map[string]error{
+		"https://93.184.216.34/hooks":        nil,
+		"http://[2606:4700::1111]:8080/hook": nil,
+		"ftp://93.184.216.34/hooks":          ErrInvalidWebhookURL,
+		"https:///hooks":                     ErrInvalidWebhookURL,
+		"http://127.0.0.1:8080/hook":         ErrPrivateWebhookURL,
+		"http://10.1.2.3/hook":               ErrPrivateWebhookURL,
+		"http://169.254.169.254/latest":      ErrPrivateWebhookURL,
+		"http://[::1]/hook":                  ErrPrivateWebhookURL,
+		"http://localhost/hook":              ErrPrivateWebhookURL,
+	}
+	for raw, want := range tests {
+		if err := validateWebhookURL(raw); !errors.Is(err, want) || (want == nil && err != nil) {
+			t.Errorf("validateWebhookURL(%q) = %v, want %v", raw, err, want)
+		}
+	}
+}
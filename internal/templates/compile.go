@@ -0,0 +1,114 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"notification-service/internal/models"
+	texttemplate "text/template"
+)
+
+// Compiled holds the parsed parts of a stored template version
+type Compiled struct {
+	subject *texttemplate.Template
+	html    *template.Template
+	text    *texttemplate.Template
+	title   *texttemplate.Template
+	body    *texttemplate.Template
+}
+
+// Compile parses a stored version for its channel with the helpers of its locale, checking the
+// parts the channel requires. Versions must compile before they can be published.
+func Compile(channel, locale string, version *models.TemplateVersion) (*Compiled, error) {
+	var compiled Compiled
+	var err error
+	funcs := localeFuncs(locale)
+
+	switch channel {
+	case ChannelEmail:
+		if version.Subject == "" || version.HTML == "" {
+			return nil, errors.New("email templates need a subject and html")
+		}
+		if compiled.subject, err = texttemplate.New("subject").Funcs(funcs).Parse(version.Subject); err != nil {
+			return nil, fmt.Errorf("parse subject: %w", err)
+		}
+		if compiled.html, err = template.New("html").Funcs(funcs).Parse(version.HTML); err != nil {
+			return nil, fmt.Errorf("parse html: %w", err)
+		}
+		if version.Text != "" {
+			if compiled.text, err = texttemplate.New("text").Funcs(funcs).Parse(version.Text); err != nil {
+				return nil, fmt.Errorf("parse text: %w", err)
+			}
+		}
+	case ChannelPush:
+		if version.Title == "" || version.Body == "" {
+			return nil, errors.New("push templates need a title and body")
+		}
+		if compiled.title, err = texttemplate.New("title").Funcs(funcs).Parse(version.Title); err != nil {
+			return nil, fmt.Errorf("parse title: %w", err)
+		}
+		if compiled.body, err = texttemplate.New("body").Funcs(funcs).Parse(version.Body); err != nil {
+			return nil, fmt.Errorf("parse body: %w", err)
+		}
+	case ChannelSMS:
+		if version.Body == "" {
+			return nil, errors.New("sms templates need a body")
+		}
+		if compiled.body, err = texttemplate.New("body").Funcs(funcs).Parse(version.Body); err != nil {
+			return nil, fmt.Errorf("parse body: %w", err)
+		}
+	case ChannelChat:
+		if version.Body == "" {
+			return nil, errors.New("chat templates need a body")
+		}
+		if compiled.title, err = texttemplate.New("title").Funcs(funcs).Parse(version.Title); err != nil {
+			return nil, fmt.Errorf("parse title: %w", err)
+		}
+		if compiled.body, err = texttemplate.New("body").Funcs(funcs).Parse(version.Body); err != nil {
+			return nil, fmt.Errorf("parse body: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported template channel: %s", channel)
+	}
+
+	return &compiled, nil
+}
+
+func (c *Compiled) execute(data interface{}) (*Rendered, error) {
+	var rendered Rendered
+	parts := []struct {
+		name string
+		exec func(*bytes.Buffer) error
+		out  *string
+	}{
+		{"subject", func(b *bytes.Buffer) error { return executeText(c.subject, b, data) }, &rendered.Subject},
+		{"html", func(b *bytes.Buffer) error { return executeHTML(c.html, b, data) }, &rendered.HTML},
+		{"text", func(b *bytes.Buffer) error { return executeText(c.text, b, data) }, &rendered.Text},
+		{"title", func(b *bytes.Buffer) error { return executeText(c.title, b, data) }, &rendered.Title},
+		{"body", func(b *bytes.Buffer) error { return executeText(c.body, b, data) }, &rendered.Body},
+	}
+
+	for _, part := range parts {
+		var out bytes.Buffer
+		if err := part.exec(&out); err != nil {
+			return nil, fmt.Errorf("render %s: %w", part.name, err)
+		}
+		*part.out = out.String()
+	}
+	return &rendered, nil
+}
+
+func executeText(t *texttemplate.Template, out *bytes.Buffer, data interface{}) error {
+	if t == nil {
+		return nil
+	}
+	return t.Execute(out, data)
+}
+
+func executeHTML(t *template.Template, out *bytes.Buffer, data interface{}) error {
+	if t == nil {
+		return nil
+	}
+	return t.Execute(out, data)
+}
@@ -0,0 +1,88 @@
+package templates
+
+// builtinEmailTemplates lists the templates embedded under email/, each with its own variables.
+// English bodies live in email/ and other locales in email/<locale>/, one per subject listed.
+var builtinEmailTemplates = []EmailTemplate{
+	{
+		Name: PasswordReset,
+		Subjects: map[string]string{
+			"en": "Reset Your Password",
+			"id": "Atur Ulang Kata Sandi Anda",
+		},
+		newData: func() interface{} { return &PasswordResetData{} },
+		sample: func() interface{} {
+			return &PasswordResetData{FullName: "Jane Doe", URL: "https://example.com/reset"}
+		},
+	},
+	{
+		Name: Welcome,
+		Subjects: map[string]string{
+			"en": "Welcome to My Home, {{.FullName}}",
+			"id": "Selamat Datang di My Home, {{.FullName}}",
+		},
+		newData: func() interface{} { return &WelcomeData{} },
+		sample: func() interface{} {
+			return &WelcomeData{FullName: "Jane Doe", URL: "https://example.com"}
+		},
+	},
+	{
+		Name: EmailVerification,
+		Subjects: map[string]string{
+			"en": "Verify Your Email Address",
+			"id": "Verifikasi Alamat Email Anda",
+		},
+		newData: func() interface{} { return &EmailVerificationData{ExpiresInMinutes: 30} },
+		sample: func() interface{} {
+			return &EmailVerificationData{FullName: "Jane Doe", URL: "https://example.com/verify", Code: "123456", ExpiresInMinutes: 30}
+		},
+	},
+	{
+		Name: AssetReport,
+		Subjects: map[string]string{
+			"en": "Your Asset Report for {{.Period}}",
+			"id": "Laporan Aset Anda untuk {{.Period}}",
+		},
+		newData: func() interface{} { return &AssetReportData{} },
+		sample: func() interface{} {
+			return &AssetReportData{
+				FullName:   "Jane Doe",
+				Period:     "January 2025",
+				Assets:     []AssetReportItem{{Name: "Laptop", Category: "Electronics", Status: "active", Value: "1,000"}},
+				TotalValue: "1,000",
+				URL:        "https://example.com/assets",
+			}
+		},
+	},
+}
+
+type PasswordResetData struct {
+	FullName string `json:"full_name" binding:"required"`
+	URL      string `json:"url" binding:"required,url"`
+}
+
+type WelcomeData struct {
+	FullName string `json:"full_name" binding:"required"`
+	URL      string `json:"url" binding:"required,url"`
+}
+
+type EmailVerificationData struct {
+	FullName         string `json:"full_name" binding:"required"`
+	URL              string `json:"url" binding:"required,url"`
+	Code             string `json:"code"`
+	ExpiresInMinutes int    `json:"expires_in_minutes"`
+}
+
+type AssetReportData struct {
+	FullName   string            `json:"full_name" binding:"required"`
+	Period     string            `json:"period" binding:"required"`
+	Assets     []AssetReportItem `json:"assets" binding:"dive"`
+	TotalValue string            `json:"total_value"`
+	URL        string            `json:"url" binding:"omitempty,url"`
+}
+
+type AssetReportItem struct {
+	Name     string `json:"name" binding:"required"`
+	Category string `json:"category"`
+	Status   string `json:"status"`
+	Value    string `json:"value"`
+}
@@ -0,0 +1,199 @@
+package templates
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLocale ends every fallback chain
+const DefaultLocale = "en"
+
+// NormalizeLocale turns "id_id" or "ID-id" into "id-ID"; empty input stays empty
+func NormalizeLocale(locale string) string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if locale == "" {
+		return ""
+	}
+
+	parts := strings.Split(locale, "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i])
+	}
+	return strings.Join(parts, "-")
+}
+
+// LocaleChain lists the locales to try in order, e.g. "id-ID" → "id-ID", "id", "en"
+func LocaleChain(locale string) []string {
+	locale = NormalizeLocale(locale)
+
+	var chain []string
+	for locale != "" {
+		chain = append(chain, locale)
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	if len(chain) == 0 || chain[len(chain)-1] != DefaultLocale {
+		chain = append(chain, DefaultLocale)
+	}
+	return chain
+}
+
+// localeFormat holds the conventions the template helpers need for one language
+type localeFormat struct {
+	months        [12]string
+	dateLayout    string // "{d}", "{month}" and "{yyyy}" are replaced
+	timeLayout    string
+	thousands     string
+	decimal       string
+	noPluralForms bool // languages like Indonesian do not inflect nouns for number
+}
+
+var localeFormats = map[string]localeFormat{
+	"en": {
+		months:     [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		dateLayout: "{month} {d}, {yyyy}",
+		timeLayout: "15:04",
+		thousands:  ",",
+		decimal:    ".",
+	},
+	"id": {
+		months:        [12]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+		dateLayout:    "{d} {month} {yyyy}",
+		timeLayout:    "15.04",
+		thousands:     ".",
+		decimal:       ",",
+		noPluralForms: true,
+	},
+}
+
+// formatFor returns the conventions of the locale's language, or English when it has none
+func formatFor(locale string) localeFormat {
+	for _, candidate := range LocaleChain(locale) {
+		if format, ok := localeFormats[strings.SplitN(candidate, "-", 2)[0]]; ok {
+			return format
+		}
+	}
+	return localeFormats[DefaultLocale]
+}
+
+// localeFuncs returns the helpers available to every template rendered for the locale:
+//
+//	{{locale}}                                 the resolved locale, e.g. for <html lang>
+//	{{plural .Count "asset" "assets"}}         the form matching the count
+//	{{formatDate .DueAt}}                      "2 Januari 2025" / "January 2, 2025"
+//	{{formatDateTime .DueAt}}                  date followed by the local time format
+//	{{formatNumber .Total}}                    "1.234.567" / "1,234,567"
+func localeFuncs(locale string) map[string]interface{} {
+	format := formatFor(locale)
+	return map[string]interface{}{
+		"locale": func() string { return locale },
+		"plural": func(count interface{}, one, other string) (string, error) {
+			n, err := toFloat(count)
+			if err != nil {
+				return "", err
+			}
+			if n == 1 && !format.noPluralForms {
+				return one, nil
+			}
+			return other, nil
+		},
+		"formatDate": func(value interface{}) (string, error) {
+			t, err := toTime(value)
+			if err != nil {
+				return "", err
+			}
+			return format.date(t), nil
+		},
+		"formatDateTime": func(value interface{}) (string, error) {
+			t, err := toTime(value)
+			if err != nil {
+				return "", err
+			}
+			return format.date(t) + " " + t.Format(format.timeLayout), nil
+		},
+		"formatNumber": func(value interface{}) (string, error) {
+			n, err := toFloat(value)
+			if err != nil {
+				return "", err
+			}
+			return format.number(n), nil
+		},
+	}
+}
+
+func (f localeFormat) date(t time.Time) string {
+	return strings.NewReplacer(
+		"{d}", strconv.Itoa(t.Day()),
+		"{month}", f.months[t.Month()-1],
+		"{yyyy}", strconv.Itoa(t.Year()),
+	).Replace(f.dateLayout)
+}
+
+// number groups thousands and keeps two decimals only when the value has a fraction
+func (f localeFormat) number(n float64) string {
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	whole, frac := math.Modf(math.Round(n*100) / 100)
+	digits := strconv.FormatFloat(whole, 'f', 0, 64)
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteString(f.thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	out := sign + grouped.String()
+	if cents := math.Round(frac * 100); cents > 0 {
+		out += fmt.Sprintf("%s%02d", f.decimal, int(cents))
+	}
+	return out
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("not a number: %v", value)
+	}
+}
+
+// toTime accepts times as well as RFC 3339 or plain date strings, since map variables arrive as JSON
+func toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, fmt.Errorf("nil time")
+		}
+		return *v, nil
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		}
+		return time.Parse(time.DateOnly, v)
+	default:
+		return time.Time{}, fmt.Errorf("not a time: %v", value)
+	}
+}
@@ -0,0 +1,294 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"notification-service/internal/models"
+	"notification-service/internal/repository"
+	"sort"
+	texttemplate "text/template"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+//go:embed email
+var emailFS embed.FS
+
+// Names of the built-in email templates
+const (
+	PasswordReset     = "password_reset"
+	Welcome           = "welcome"
+	EmailVerification = "email_verification"
+	AssetReport       = "asset_report"
+)
+
+// transactional lists the emails a user needs to act on their account; they are never tracked
+// unless the event asks for it
+var transactional = map[string]bool{
+	PasswordReset:     true,
+	EmailVerification: true,
+}
+
+// IsTransactional reports whether the named email template is transactional
+func IsTransactional(name string) bool {
+	return transactional[name]
+}
+
+// Template channels
+const (
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+	ChannelSMS   = "sms"
+	ChannelChat  = "chat"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+// EmailTemplate describes a named email: its subject line per locale and the typed variables it
+// renders with. sample fills every field, so stored overrides can be test-executed before publishing.
+type EmailTemplate struct {
+	Name     string
+	Subjects map[string]string
+	newData  func() interface{}
+	sample   func() interface{}
+}
+
+// Rendered is the output of a template; email templates fill Subject, HTML and Text, push
+// templates fill Title and Body
+type Rendered struct {
+	Template string
+	Version  int    // zero for the embedded templates
+	Locale   string // the locale of the fallback chain that matched
+	Subject  string
+	HTML     string
+	Text     string
+	Title    string
+	Body     string
+}
+
+type Registry interface {
+	RenderEmail(name, locale string, variables json.RawMessage) (*Rendered, error)
+	RenderPush(name, locale string, variables json.RawMessage) (*Rendered, error)
+	RenderSMS(name, locale string, variables json.RawMessage) (*Rendered, error)
+	RenderChat(name, locale string, variables json.RawMessage) (*Rendered, error)
+	RenderVersion(template models.Template, version models.TemplateVersion, variables json.RawMessage) (*Rendered, error)
+	Validate(template models.Template, version models.TemplateVersion) error
+	EmailTemplates() []string
+}
+
+type registry struct {
+	repo  repository.TemplateRepository
+	email map[string]map[string]emailEntry // name → locale → entry
+}
+
+type emailEntry struct {
+	EmailTemplate
+	subject *texttemplate.Template
+	html    *template.Template
+}
+
+// NewRegistry parses the embedded templates once so bad markup fails at startup, not per email.
+// Published versions stored through the template API take precedence over the embedded ones.
+func NewRegistry(repo repository.TemplateRepository) (Registry, error) {
+	r := &registry{repo: repo, email: make(map[string]map[string]emailEntry)}
+	for _, t := range builtinEmailTemplates {
+		r.email[t.Name] = make(map[string]emailEntry)
+		for locale, subjectText := range t.Subjects {
+			funcs := localeFuncs(locale)
+			subject, err := texttemplate.New(t.Name + ".subject").Funcs(funcs).Parse(subjectText)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s %s subject: %w", locale, t.Name, err)
+			}
+
+			body := "email/" + t.Name + ".html"
+			if locale != DefaultLocale {
+				body = "email/" + locale + "/" + t.Name + ".html"
+			}
+			html, err := template.New("layout.html").Funcs(funcs).ParseFS(emailFS, "email/layout.html", body)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s %s html: %w", locale, t.Name, err)
+			}
+
+			r.email[t.Name][locale] = emailEntry{EmailTemplate: t, subject: subject, html: html}
+		}
+		if _, ok := r.email[t.Name][DefaultLocale]; !ok {
+			return nil, fmt.Errorf("%s has no %s version", t.Name, DefaultLocale)
+		}
+	}
+	return r, nil
+}
+
+// RenderEmail walks the locale fallback chain; at each locale the published stored version
+// wins over the embedded one
+func (r *registry) RenderEmail(name, locale string, variables json.RawMessage) (*Rendered, error) {
+	for _, candidate := range LocaleChain(locale) {
+		rendered, found, err := r.renderPublished(ChannelEmail, name, candidate, variables)
+		if err != nil || found {
+			return rendered, err
+		}
+
+		if entry, ok := r.email[name][candidate]; ok {
+			return renderEmbedded(entry, candidate, variables)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+// RenderPush renders the published push template closest to the locale; push templates only
+// exist in the database
+func (r *registry) RenderPush(name, locale string, variables json.RawMessage) (*Rendered, error) {
+	return r.renderStored(ChannelPush, name, locale, variables)
+}
+
+// RenderSMS renders the published SMS template closest to the locale into Body
+func (r *registry) RenderSMS(name, locale string, variables json.RawMessage) (*Rendered, error) {
+	return r.renderStored(ChannelSMS, name, locale, variables)
+}
+
+// RenderChat renders the published chat template closest to the locale into Title and Body
+func (r *registry) RenderChat(name, locale string, variables json.RawMessage) (*Rendered, error) {
+	return r.renderStored(ChannelChat, name, locale, variables)
+}
+
+// renderStored renders the published template closest to the locale for channels that only
+// exist in the database
+func (r *registry) renderStored(channel, name, locale string, variables json.RawMessage) (*Rendered, error) {
+	for _, candidate := range LocaleChain(locale) {
+		rendered, found, err := r.renderPublished(channel, name, candidate, variables)
+		if err != nil || found {
+			return rendered, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+func renderEmbedded(entry emailEntry, locale string, variables json.RawMessage) (*Rendered, error) {
+	name := entry.Name
+	data, err := decodeVariables(ChannelEmail, name, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	var subject bytes.Buffer
+	if err := entry.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("render %s subject: %w", name, err)
+	}
+
+	var html bytes.Buffer
+	if err := entry.html.ExecuteTemplate(&html, "layout.html", data); err != nil {
+		return nil, fmt.Errorf("render %s html: %w", name, err)
+	}
+
+	return &Rendered{Template: name, Locale: locale, Subject: subject.String(), HTML: html.String()}, nil
+}
+
+// RenderVersion renders any version, published or not, which is what previews need
+func (r *registry) RenderVersion(template models.Template, version models.TemplateVersion, variables json.RawMessage) (*Rendered, error) {
+	compiled, err := Compile(template.Channel, template.Locale, &version)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decodeVariables(template.Channel, template.Name, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := compiled.execute(data)
+	if err != nil {
+		return nil, err
+	}
+	rendered.Template = template.Name
+	rendered.Version = version.Version
+	rendered.Locale = template.Locale
+	return rendered, nil
+}
+
+// Validate compiles the version and, for overrides of a built-in email, executes it against the
+// built-in's typed sample data, so a version addressing fields the typed variables lack cannot be published
+func (r *registry) Validate(template models.Template, version models.TemplateVersion) error {
+	compiled, err := Compile(template.Channel, template.Locale, &version)
+	if err != nil {
+		return err
+	}
+
+	entry, builtin := builtinTemplate(template.Channel, template.Name)
+	if !builtin {
+		return nil
+	}
+	_, err = compiled.execute(entry.sample())
+	return err
+}
+
+func (r *registry) EmailTemplates() []string {
+	names := make([]string, 0, len(r.email))
+	for name := range r.email {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *registry) renderPublished(channel, name, locale string, variables json.RawMessage) (*Rendered, bool, error) {
+	stored, err := r.repo.FindByName(channel, name, locale)
+	if err != nil {
+		return nil, false, fmt.Errorf("find template: %w", err)
+	}
+	if stored == nil {
+		return nil, false, nil
+	}
+
+	version, err := r.repo.FindPublishedVersion(stored.ID)
+	if err != nil {
+		return nil, false, fmt.Errorf("find published version: %w", err)
+	}
+	if version == nil {
+		return nil, false, nil
+	}
+
+	rendered, err := r.RenderVersion(*stored, *version, variables)
+	return rendered, true, err
+}
+
+// decodeVariables decodes into the typed data of a built-in email template, validating its binding
+// tags, or into a plain map that templates address by JSON key for everything else, including
+// other channels' templates that happen to share a built-in email's name
+func decodeVariables(channel, name string, variables json.RawMessage) (interface{}, error) {
+	entry, builtin := builtinTemplate(channel, name)
+	if !builtin {
+		data := map[string]interface{}{}
+		if len(variables) > 0 {
+			if err := json.Unmarshal(variables, &data); err != nil {
+				return nil, fmt.Errorf("decode %s variables: %w", name, err)
+			}
+		}
+		return data, nil
+	}
+
+	data := entry.newData()
+	if len(variables) > 0 {
+		if err := json.Unmarshal(variables, data); err != nil {
+			return nil, fmt.Errorf("decode %s variables: %w", name, err)
+		}
+	}
+	if err := binding.Validator.ValidateStruct(data); err != nil {
+		return nil, fmt.Errorf("invalid %s variables: %w", name, err)
+	}
+	return data, nil
+}
+
+func builtinTemplate(channel, name string) (EmailTemplate, bool) {
+	if channel != ChannelEmail {
+		return EmailTemplate{}, false
+	}
+	for _, t := range builtinEmailTemplates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return EmailTemplate{}, false
+}
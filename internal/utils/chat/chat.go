@@ -0,0 +1,190 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"notification-service/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Platforms a Destination can point at
+const (
+	PlatformSlack    = "slack"    // incoming webhook, Block Kit payload
+	PlatformTelegram = "telegram" // Bot API sendMessage to a chat ID
+	PlatformDiscord  = "discord"  // channel webhook, embed payload
+)
+
+// Severities pick the color or emoji a message is shown with
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// ErrRejected marks messages the platform refused outright, such as a revoked webhook or an
+// unknown chat, which resending cannot fix
+var ErrRejected = errors.New("chat message rejected")
+
+// RateLimitError is returned when the destination is rate limited for longer than the sender
+// is willing to wait; the message can be retried after RetryAfter
+type RateLimitError struct {
+	Platform   string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s rate limited, retry after %s", e.Platform, e.RetryAfter)
+}
+
+// RetryDelay lets the delivery sweeper schedule the retry without knowing about chat platforms
+func (e *RateLimitError) RetryDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// Message is platform neutral; each platform lays it out in its own format. Text is plain text
+// and escaped for the platform.
+type Message struct {
+	Title    string
+	Text     string
+	Fields   []Field
+	URL      string
+	Severity string
+	Footer   string
+}
+
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Destination struct {
+	Platform string
+	URL      string // Slack or Discord webhook URL
+	ChatID   string // Telegram chat, e.g. -1001234567890 or @ops_alerts
+}
+
+type Sender interface {
+	Send(destination Destination, message *Message) error
+}
+
+type Config struct {
+	TelegramToken    string
+	TelegramEndpoint string        // defaults to https://api.telegram.org; point it at a stub when testing
+	Timeout          time.Duration // per request, defaults to 10s
+	MaxWait          time.Duration // longest a send waits out a rate limit before giving up, defaults to 5s
+}
+
+type sender struct {
+	cfg     Config
+	client  *http.Client
+	limiter *limiter
+}
+
+// Minimum spacing between messages to one destination; Telegram allows about one message per
+// second in a chat and Slack one per second per webhook. Discord is paced by its headers.
+var platformIntervals = map[string]time.Duration{
+	PlatformSlack:    time.Second,
+	PlatformTelegram: time.Second,
+}
+
+func NewSender(cfg Config) Sender {
+	if cfg.TelegramEndpoint == "" {
+		cfg.TelegramEndpoint = defaultTelegramEndpoint
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = 5 * time.Second
+	}
+	// Webhook URLs come from API input, so they must not reach internal hosts
+	return &sender{cfg: cfg, client: utils.NewPublicHTTPClient(cfg.Timeout), limiter: newLimiter()}
+}
+
+// Send waits for the destination's rate limit, posts the message and, when the platform answers
+// 429 with a short enough retry-after, waits it out and tries once more
+func (s *sender) Send(destination Destination, message *Message) error {
+	var build func(Destination, *Message) (*http.Request, error)
+	key := destination.Platform + ":" + destination.URL
+	switch destination.Platform {
+	case PlatformSlack:
+		build = slackRequest
+	case PlatformTelegram:
+		build = s.telegramRequest
+		key = destination.Platform + ":" + destination.ChatID
+	case PlatformDiscord:
+		build = discordRequest
+	default:
+		return fmt.Errorf("%w: unsupported chat platform %q", ErrRejected, destination.Platform)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := s.limiter.wait(key, destination.Platform, platformIntervals[destination.Platform], s.cfg.MaxWait); err != nil {
+			return err
+		}
+
+		req, err := build(destination, message)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrRejected, err)
+		}
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("%s request: %w", destination.Platform, withoutURL(err))
+		}
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+
+		if destination.Platform == PlatformDiscord {
+			s.limiter.blockFromHeaders(key, resp.Header)
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			wait := retryAfter(resp.Header, detail)
+			s.limiter.block(key, wait)
+			if attempt == 0 && wait <= s.cfg.MaxWait {
+				continue
+			}
+			return &RateLimitError{Platform: destination.Platform, RetryAfter: wait}
+		}
+
+		// A 401 usually means a bot token being rotated, which fixing the config clears up; a
+		// missing chat or revoked webhook does not come back
+		err = fmt.Errorf("%s responded %s: %s", destination.Platform, resp.Status, strings.TrimSpace(string(detail)))
+		switch resp.StatusCode {
+		case http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+			return fmt.Errorf("%w: %w", ErrRejected, err)
+		default:
+			return err
+		}
+	}
+}
+
+// retryAfter reads the wait from the Retry-After header, Discord's retry_after body field or
+// Telegram's parameters.retry_after, defaulting to one second
+func retryAfter(header http.Header, body []byte) time.Duration {
+	if seconds, err := strconv.ParseFloat(header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if seconds := bodyRetryAfter(body); seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return time.Second
+}
+
+// withoutURL drops the request URL from a client error: Slack and Discord webhook URLs and the
+// Telegram bot token in the path are credentials, and errors end up in logs and last_error
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
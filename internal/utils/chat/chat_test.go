@@ -0,0 +1,273 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// standIn is a chat platform stand-in answering each request with the next response, repeating
+// the last one, and keeping the decoded bodies it received
+type standIn struct {
+	server    *httptest.Server
+	requests  atomic.Int32
+	paths     []string
+	bodies    []map[string]interface{}
+	responses []standInResponse
+}
+
+type standInResponse struct {
+	status int
+	header map[string]string
+	body   string
+}
+
+func newStandIn(t *testing.T, responses ...standInResponse) *standIn {
+	t.Helper()
+	s := &standIn{responses: responses}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(s.requests.Add(1))
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Errorf("request body %s is not JSON: %v", raw, err)
+		}
+		s.paths = append(s.paths, r.URL.Path)
+		s.bodies = append(s.bodies, body)
+
+		response := s.responses[min(n, len(s.responses))-1]
+		for key, value := range response.header {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(response.status)
+		w.Write([]byte(response.body))
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+var ok = standInResponse{status: http.StatusOK, body: `{"ok":true}`}
+
+func testMessage() *Message {
+	return &Message{
+		Title:    "Disk almost full",
+		Text:     "Volume <data> is at 95% on *db-1*",
+		Fields:   []Field{{Name: "Host", Value: "db-1"}},
+		URL:      "https://grafana.example.com/d/disk",
+		Severity: SeverityCritical,
+		Footer:   "disk_usage",
+	}
+}
+
+// testSender uses a plain client, since the stand-ins listen on loopback addresses the public
+// client refuses
+func testSender(telegramEndpoint string) Sender {
+	cfg := Config{TelegramToken: "123:abc", TelegramEndpoint: telegramEndpoint, Timeout: time.Second, MaxWait: 200 * time.Millisecond}
+	return &sender{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}, limiter: newLimiter()}
+}
+
+// get walks decoded JSON by object keys and array indexes
+func get(value interface{}, path ...interface{}) interface{} {
+	for _, step := range path {
+		switch key := step.(type) {
+		case string:
+			object, _ := value.(map[string]interface{})
+			value = object[key]
+		case int:
+			array, _ := value.([]interface{})
+			if key >= len(array) {
+				return nil
+			}
+			value = array[key]
+		}
+	}
+	return value
+}
+
+func TestSlackPayload(t *testing.T) {
+	platform := newStandIn(t, ok)
+	if err := testSender("").Send(Destination{Platform: PlatformSlack, URL: platform.server.URL}, testMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	body := platform.bodies[0]
+	if body["text"] != "Disk almost full" {
+		t.Errorf("fallback text = %v", body["text"])
+	}
+	checks := map[string][2]interface{}{
+		"header type":    {get(body, "blocks", 0, "type"), "header"},
+		"header text":    {get(body, "blocks", 0, "text", "text"), "🚨 Disk almost full"},
+		"escaped text":   {get(body, "blocks", 1, "text", "text"), "Volume &lt;data&gt; is at 95% on *db-1*"},
+		"field":          {get(body, "blocks", 2, "fields", 0, "text"), "*Host*\ndb-1"},
+		"button url":     {get(body, "blocks", 3, "elements", 0, "url"), "https://grafana.example.com/d/disk"},
+		"footer":         {get(body, "blocks", 4, "elements", 0, "text"), "disk_usage"},
+		"text is mrkdwn": {get(body, "blocks", 1, "text", "type"), "mrkdwn"},
+	}
+	for name, check := range checks {
+		if check[0] != check[1] {
+			t.Errorf("%s = %v, want %v", name, check[0], check[1])
+		}
+	}
+}
+
+func TestTelegramPayload(t *testing.T) {
+	platform := newStandIn(t, ok)
+	if err := testSender(platform.server.URL).Send(Destination{Platform: PlatformTelegram, ChatID: "-1001234567890"}, testMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if platform.paths[0] != "/bot123:abc/sendMessage" {
+		t.Errorf("path = %s", platform.paths[0])
+	}
+	body := platform.bodies[0]
+	want := "<b>🚨 Disk almost full</b>\nVolume &lt;data&gt; is at 95% on *db-1*\n\n<b>Host:</b> db-1\n\n<i>disk_usage</i>"
+	if body["text"] != want {
+		t.Errorf("text = %q, want %q", body["text"], want)
+	}
+	if body["chat_id"] != "-1001234567890" || body["parse_mode"] != "HTML" {
+		t.Errorf("chat_id = %v, parse_mode = %v", body["chat_id"], body["parse_mode"])
+	}
+	if url := get(body, "reply_markup", "inline_keyboard", 0, 0, "url"); url != "https://grafana.example.com/d/disk" {
+		t.Errorf("button url = %v", url)
+	}
+}
+
+func TestTelegramWithoutToken(t *testing.T) {
+	err := NewSender(Config{}).Send(Destination{Platform: PlatformTelegram, ChatID: "1"}, testMessage())
+	if !errors.Is(err, ErrRejected) {
+		t.Errorf("error = %v, want ErrRejected", err)
+	}
+}
+
+func TestDiscordPayload(t *testing.T) {
+	platform := newStandIn(t, standInResponse{status: http.StatusNoContent})
+	if err := testSender("").Send(Destination{Platform: PlatformDiscord, URL: platform.server.URL}, testMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	body := platform.bodies[0]
+	checks := map[string][2]interface{}{
+		"title":       {get(body, "embeds", 0, "title"), "🚨 Disk almost full"},
+		"description": {get(body, "embeds", 0, "description"), `Volume <data\> is at 95% on \*db-1\*`},
+		"url":         {get(body, "embeds", 0, "url"), "https://grafana.example.com/d/disk"},
+		"color":       {get(body, "embeds", 0, "color"), float64(0xe74c3c)},
+		"field":       {get(body, "embeds", 0, "fields", 0, "value"), "db-1"},
+		"footer":      {get(body, "embeds", 0, "footer", "text"), "disk_usage"},
+	}
+	for name, check := range checks {
+		if check[0] != check[1] {
+			t.Errorf("%s = %v, want %v", name, check[0], check[1])
+		}
+	}
+	if parse, isArray := get(body, "allowed_mentions", "parse").([]interface{}); !isArray || len(parse) != 0 {
+		t.Errorf("allowed_mentions.parse = %v, want []", get(body, "allowed_mentions", "parse"))
+	}
+}
+
+func TestRateLimitWaitedOut(t *testing.T) {
+	platform := newStandIn(t, standInResponse{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "0.05"}}, ok)
+	if err := testSender("").Send(Destination{Platform: PlatformDiscord, URL: platform.server.URL}, testMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if n := platform.requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+}
+
+func TestRateLimitTooLong(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		response standInResponse
+		want     time.Duration
+	}{
+		{"Retry-After header", PlatformSlack, standInResponse{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "30"}}, 30 * time.Second},
+		{"discord body", PlatformDiscord, standInResponse{status: http.StatusTooManyRequests, body: `{"message":"You are being rate limited.","retry_after":1.5,"global":false}`}, 1500 * time.Millisecond},
+		{"telegram body", PlatformTelegram, standInResponse{status: http.StatusTooManyRequests, body: `{"ok":false,"error_code":429,"parameters":{"retry_after":12}}`}, 12 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			platform := newStandIn(t, tt.response)
+			err := testSender(platform.server.URL).Send(Destination{Platform: tt.platform, URL: platform.server.URL, ChatID: "1"}, testMessage())
+
+			var rateLimited *RateLimitError
+			if !errors.As(err, &rateLimited) {
+				t.Fatalf("error = %v, want a RateLimitError", err)
+			}
+			if rateLimited.RetryAfter != tt.want {
+				t.Errorf("RetryAfter = %s, want %s", rateLimited.RetryAfter, tt.want)
+			}
+			if n := platform.requests.Load(); n != 1 {
+				t.Errorf("made %d requests, want 1", n)
+			}
+		})
+	}
+}
+
+func TestDiscordBucketHeaders(t *testing.T) {
+	platform := newStandIn(t, standInResponse{
+		status: http.StatusNoContent,
+		header: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset-After": "20"},
+	})
+	sender := testSender("")
+	destination := Destination{Platform: PlatformDiscord, URL: platform.server.URL}
+	if err := sender.Send(destination, testMessage()); err != nil {
+		t.Fatalf("first send: %v", err)
+	}
+
+	// The exhausted bucket is respected without asking Discord again
+	err := sender.Send(destination, testMessage())
+	var rateLimited *RateLimitError
+	if !errors.As(err, &rateLimited) || rateLimited.RetryAfter < 19*time.Second {
+		t.Fatalf("second send error = %v, want a RateLimitError of about 20s", err)
+	}
+	if n := platform.requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+}
+
+func TestSendStatus(t *testing.T) {
+	tests := []struct {
+		status   int
+		rejected bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusForbidden, true},
+		{http.StatusNotFound, true},
+		{http.StatusGone, true},
+		{http.StatusUnauthorized, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusBadGateway, false},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			platform := newStandIn(t, standInResponse{status: tt.status, body: `{"ok":false}`})
+			err := testSender("").Send(Destination{Platform: PlatformDiscord, URL: platform.server.URL}, testMessage())
+			if err == nil {
+				t.Fatal("send succeeded")
+			}
+			if errors.Is(err, ErrRejected) != tt.rejected {
+				t.Errorf("errors.Is(%v, ErrRejected) = %v, want %v", err, !tt.rejected, tt.rejected)
+			}
+		})
+	}
+}
+
+func TestSendErrorHidesCredentials(t *testing.T) {
+	platform := newStandIn(t, ok)
+	platform.server.Close()
+
+	err := testSender(platform.server.URL).Send(Destination{Platform: PlatformTelegram, ChatID: "1"}, testMessage())
+	if err == nil {
+		t.Fatal("send succeeded without a server")
+	}
+	if strings.Contains(err.Error(), "123:abc") {
+		t.Errorf("error %q contains the bot token", err)
+	}
+}
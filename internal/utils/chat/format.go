@@ -0,0 +1,231 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+)
+
+const defaultTelegramEndpoint = "https://api.telegram.org"
+
+var severityEmoji = map[string]string{
+	SeverityInfo:     "ℹ️",
+	SeverityWarning:  "⚠️",
+	SeverityCritical: "🚨",
+}
+
+var severityColor = map[string]int{
+	SeverityInfo:     0x3498db,
+	SeverityWarning:  0xf1c40f,
+	SeverityCritical: 0xe74c3c,
+}
+
+// Platform limits on the parts of a message
+const (
+	slackHeaderLimit     = 150
+	slackTextLimit       = 3000
+	slackFieldsPerBlock  = 10
+	telegramTextLimit    = 4096
+	discordTitleLimit    = 256
+	discordTextLimit     = 4096
+	discordFieldLimit    = 1024
+	discordFieldsPerPost = 25
+)
+
+type slackText struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+type slackBlock struct {
+	Type     string        `json:"type"`
+	Text     *slackText    `json:"text,omitempty"`
+	Fields   []slackText   `json:"fields,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+type slackButton struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+	URL  string    `json:"url"`
+}
+
+// slackRequest lays the message out in Block Kit with a plain-text fallback for notifications
+func slackRequest(destination Destination, message *Message) (*http.Request, error) {
+	var blocks []slackBlock
+	if message.Title != "" {
+		blocks = append(blocks, slackBlock{Type: "header", Text: &slackText{Type: "plain_text", Text: truncate(titleWithEmoji(message), slackHeaderLimit), Emoji: true}})
+	}
+	if message.Text != "" {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: truncate(slackEscape(message.Text), slackTextLimit)}})
+	}
+	for start := 0; start < len(message.Fields); start += slackFieldsPerBlock {
+		block := slackBlock{Type: "section"}
+		for _, field := range message.Fields[start:min(start+slackFieldsPerBlock, len(message.Fields))] {
+			block.Fields = append(block.Fields, slackText{Type: "mrkdwn", Text: "*" + slackEscape(field.Name) + "*\n" + slackEscape(field.Value)})
+		}
+		blocks = append(blocks, block)
+	}
+	if message.URL != "" {
+		button := slackButton{Type: "button", Text: slackText{Type: "plain_text", Text: "Open"}, URL: message.URL}
+		blocks = append(blocks, slackBlock{Type: "actions", Elements: []interface{}{button}})
+	}
+	if message.Footer != "" {
+		blocks = append(blocks, slackBlock{Type: "context", Elements: []interface{}{slackText{Type: "mrkdwn", Text: slackEscape(message.Footer)}}})
+	}
+
+	fallback := message.Title
+	if fallback == "" {
+		fallback = message.Text
+	}
+	return jsonRequest(destination.URL, map[string]interface{}{"text": truncate(fallback, slackTextLimit), "blocks": blocks})
+}
+
+type telegramButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// telegramRequest sends the message as HTML to the Bot API, with the URL as an inline button
+func (s *sender) telegramRequest(destination Destination, message *Message) (*http.Request, error) {
+	if s.cfg.TelegramToken == "" {
+		return nil, fmt.Errorf("no telegram bot token is configured")
+	}
+
+	text := telegramText{budget: telegramTextLimit}
+	if message.Title != "" {
+		text.add("<b>", titleWithEmoji(message), "</b>\n")
+	}
+	if message.Text != "" {
+		text.add("", message.Text, "\n")
+	}
+	for _, field := range message.Fields {
+		text.add("\n<b>", field.Name+":", "</b> ")
+		text.add("", field.Value, "")
+	}
+	if message.Footer != "" {
+		text.add("\n\n<i>", message.Footer, "</i>")
+	}
+
+	body := map[string]interface{}{
+		"chat_id":                  destination.ChatID,
+		"text":                     strings.TrimSpace(text.String()),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	}
+	if message.URL != "" {
+		body["reply_markup"] = map[string]interface{}{
+			"inline_keyboard": [][]telegramButton{{{Text: "Open", URL: message.URL}}},
+		}
+	}
+	endpoint := strings.TrimRight(s.cfg.TelegramEndpoint, "/") + "/bot" + s.cfg.TelegramToken + "/sendMessage"
+	return jsonRequest(endpoint, body)
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	Color       int            `json:"color,omitempty"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      *struct {
+		Text string `json:"text"`
+	} `json:"footer,omitempty"`
+}
+
+// discordRequest posts one embed; mentions are disabled so alert text can never ping @everyone
+func discordRequest(destination Destination, message *Message) (*http.Request, error) {
+	embed := discordEmbed{
+		Title:       truncate(titleWithEmoji(message), discordTitleLimit),
+		Description: truncate(discordEscape(message.Text), discordTextLimit),
+		URL:         message.URL,
+		Color:       severityColor[message.Severity],
+	}
+	for _, field := range message.Fields[:min(len(message.Fields), discordFieldsPerPost)] {
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   truncate(field.Name, discordTitleLimit),
+			Value:  truncate(discordEscape(field.Value), discordFieldLimit),
+			Inline: true,
+		})
+	}
+	if message.Footer != "" {
+		embed.Footer = &struct {
+			Text string `json:"text"`
+		}{Text: message.Footer}
+	}
+
+	return jsonRequest(destination.URL, map[string]interface{}{
+		"embeds":           []discordEmbed{embed},
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	})
+}
+
+func jsonRequest(endpoint string, body interface{}) (*http.Request, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func titleWithEmoji(message *Message) string {
+	if emoji, ok := severityEmoji[message.Severity]; ok && message.Title != "" {
+		return emoji + " " + message.Title
+	}
+	return message.Title
+}
+
+// slackEscape escapes the three characters mrkdwn treats as control characters
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+var discordMarkdown = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`)
+
+func discordEscape(text string) string {
+	return discordMarkdown.Replace(text)
+}
+
+// truncate shortens text to limit characters, marking the cut with an ellipsis
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// telegramText builds an HTML message within Telegram's length limit, which counts the text
+// without markup; parts are cut before escaping so no tag or entity is ever split
+type telegramText struct {
+	strings.Builder
+	budget int
+}
+
+func (t *telegramText) add(open, text, close string) {
+	// The line breaks and spaces around the markup count too
+	t.budget -= strings.Count(open+close, "\n") + strings.Count(open+close, " ")
+	if t.budget <= 0 {
+		return
+	}
+	if runes := []rune(text); len(runes) > t.budget {
+		text = string(runes[:t.budget-1]) + "…"
+	}
+	t.budget -= len([]rune(text))
+	t.WriteString(open + html.EscapeString(text) + close)
+}
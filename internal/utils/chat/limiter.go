@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// limiter tracks, per destination, the earliest time the next message may go out
+type limiter struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newLimiter() *limiter {
+	return &limiter{next: make(map[string]time.Time)}
+}
+
+// wait sleeps until the destination is free and reserves it for interval, or returns a
+// RateLimitError without waiting when that would take longer than maxWait
+func (l *limiter) wait(key, platform string, interval, maxWait time.Duration) error {
+	l.mu.Lock()
+	now := time.Now()
+	start := now
+	if l.next[key].After(now) {
+		start = l.next[key]
+	}
+	delay := start.Sub(now)
+	if delay > maxWait {
+		l.mu.Unlock()
+		return &RateLimitError{Platform: platform, RetryAfter: delay}
+	}
+	l.next[key] = start.Add(interval)
+	l.mu.Unlock()
+
+	time.Sleep(delay)
+	return nil
+}
+
+// block keeps the destination closed for d, never shortening an existing block
+func (l *limiter) block(key string, d time.Duration) {
+	until := time.Now().Add(d)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.next[key]) {
+		l.next[key] = until
+	}
+}
+
+// blockFromHeaders honours Discord's bucket headers: once the remaining count hits zero the
+// destination stays closed until the bucket resets
+func (l *limiter) blockFromHeaders(key string, header http.Header) {
+	if header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	if seconds, err := strconv.ParseFloat(header.Get("X-RateLimit-Reset-After"), 64); err == nil && seconds > 0 {
+		l.block(key, time.Duration(seconds*float64(time.Second)))
+	}
+}
+
+// bodyRetryAfter reads retry_after in seconds from a Discord or Telegram 429 body
+func bodyRetryAfter(body []byte) float64 {
+	var payload struct {
+		RetryAfter float64 `json:"retry_after"`
+		Parameters struct {
+			RetryAfter float64 `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return 0
+	}
+	return max(payload.RetryAfter, payload.Parameters.RetryAfter)
+}
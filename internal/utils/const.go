@@ -22,3 +22,7 @@ const (
 	TableUserRolesName    = "user_roles"
 	TableResourcesName    = "resources"
 )
+
+const (
+	SubjectDeviceTokenInvalidated = "device_token_invalidated"
+)
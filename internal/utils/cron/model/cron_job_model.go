@@ -7,10 +7,12 @@ import (
 type CronJob struct {
 	ID             uint      `gorm:"primaryKey" json:"id"`
 	Name           string    `gorm:"type:varchar(100);not null" json:"name"`
-	Schedule       string    `gorm:"type:varchar(100);not null" json:"schedule"`
+	Schedule       string    `gorm:"type:varchar(100);not null" json:"schedule"` // five fields, or six with leading seconds
+	Timezone       string    `gorm:"type:varchar(64)" json:"timezone"`           // IANA name, e.g. "Asia/Jakarta"; empty uses server time
 	IsActive       bool      `gorm:"not null" json:"is_active"`
 	Description    string    `gorm:"type:text" json:"description"`
 	LastExecutedAt time.Time `gorm:"type:datetime" json:"last_executed_at"`
+	FencingToken   int64     `gorm:"default:0" json:"fencing_token"`
 	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+type CronJobRun struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	CronJobID    uint       `gorm:"not null;index" json:"cron_job_id"`
+	JobName      string     `gorm:"type:varchar(100);not null" json:"job_name"`
+	ScheduledAt  time.Time  `gorm:"not null" json:"scheduled_at"`
+	Instance     string     `gorm:"type:varchar(255);not null" json:"instance"`
+	FencingToken int64      `gorm:"default:0" json:"fencing_token"`
+	Status       string     `gorm:"type:varchar(20);not null;index" json:"status"` // "running", "success", "failed", "skipped"
+	Error        *string    `gorm:"type:text" json:"error,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"gorm.io/gorm"
 	"notification-service/internal/utils/cron/model"
+	"time"
 )
 
 type CronRepository interface {
@@ -14,6 +15,9 @@ type CronRepository interface {
 	GetCronJobByJobName(jobName string) (model.CronJob, error)
 	deleteCronJobByID(id uint) error
 	Create(m *model.CronJob) interface{}
+	UpdateLastExecuted(id uint, executedAt time.Time, fencingToken int64) (bool, error)
+	CreateCronJobRun(run *model.CronJobRun) error
+	UpdateCronJobRun(run *model.CronJobRun) error
 }
 
 type cronRepository struct {
@@ -90,3 +94,25 @@ func (r cronRepository) Create(m *model.CronJob) interface{} {
 	}
 	return nil
 }
+
+// UpdateLastExecuted records a run only if fencingToken is newer than the one already stored
+func (r cronRepository) UpdateLastExecuted(id uint, executedAt time.Time, fencingToken int64) (bool, error) {
+	result := r.db.Model(&model.CronJob{}).
+		Where("id = ? AND fencing_token < ?", id, fencingToken).
+		Updates(map[string]interface{}{
+			"last_executed_at": executedAt,
+			"fencing_token":    fencingToken,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r cronRepository) CreateCronJobRun(run *model.CronJobRun) error {
+	return r.db.Create(run).Error
+}
+
+func (r cronRepository) UpdateCronJobRun(run *model.CronJobRun) error {
+	return r.db.Save(run).Error
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"fmt"
 	"log"
+	"notification-service/internal/utils"
 	"notification-service/internal/utils/cron/model"
 	"notification-service/internal/utils/cron/repository"
+	"os"
 	"sync"
 	"time"
 
@@ -23,95 +26,331 @@ type cronService struct {
 	nats           string
 	scheduler      *cron.Cron
 	mu             sync.Mutex
-	jobs           map[uint]cron.EntryID
+	jobs           map[uint]scheduledJob
+	rejected       map[uint]string // fingerprints of jobs whose schedule failed to parse
 	cronRepository repository.CronRepository
+	redis          utils.RedisService
+	instanceID     string
+	lockTTL        time.Duration
+	syncInterval   time.Duration
+	stopSync       chan struct{}
+	stopOnce       sync.Once
+}
+
+// scheduledJob tracks a scheduler entry and the job definition it was built from
+type scheduledJob struct {
+	entryID     cron.EntryID
+	fingerprint string
 }
 
 // NewCronService initializes and returns a CronService instance
-func NewCronService(db gorm.DB, cronRepository repository.CronRepository) CronService {
+func NewCronService(db gorm.DB, cronRepository repository.CronRepository, redis utils.RedisService, lockTTL, syncInterval time.Duration) CronService {
 	return &cronService{
 		db:             db,
-		scheduler:      cron.New(), // Enables second-level precision
-		jobs:           make(map[uint]cron.EntryID),
+		scheduler:      cron.New(cron.WithParser(scheduleParser)),
+		jobs:           make(map[uint]scheduledJob),
+		rejected:       make(map[uint]string),
 		mu:             sync.Mutex{},
 		cronRepository: cronRepository,
+		redis:          redis,
+		instanceID:     newInstanceID(),
+		lockTTL:        lockTTL,
+		syncInterval:   syncInterval,
+		stopSync:       make(chan struct{}),
+	}
+}
+
+// scheduleParser accepts standard five-field specs and an optional leading seconds field
+var scheduleParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// parseSchedule resolves a job's spec in its own timezone, falling back to the server's local time
+func parseSchedule(job model.CronJob) (cron.Schedule, error) {
+	spec := job.Schedule
+	if job.Timezone != "" {
+		if _, err := time.LoadLocation(job.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", job.Timezone, err)
+		}
+		spec = "CRON_TZ=" + job.Timezone + " " + spec
+	}
+
+	schedule, err := scheduleParser.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", job.Schedule, err)
 	}
+	return schedule, nil
+}
+
+// newInstanceID identifies this replica in lock ownership and run history
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
 }
 
 func (cs *cronService) Start() {
 	cs.scheduler.Start()
-	cs.loadJobsFromDB()
+	cs.syncJobsFromDB()
+	go cs.runSyncLoop()
 }
 
+// Stop is safe to call more than once
 func (cs *cronService) Stop() {
-	cs.scheduler.Stop()
+	cs.stopOnce.Do(func() {
+		close(cs.stopSync)
+		cs.scheduler.Stop()
+	})
 }
 
-func (cs *cronService) loadJobsFromDB() {
-	var cronJobs []model.CronJob
+// runSyncLoop periodically reconciles the scheduler so edits made directly in the
+// database or by another replica are picked up without a restart
+func (cs *cronService) runSyncLoop() {
+	ticker := time.NewTicker(cs.syncInterval)
+	defer ticker.Stop()
 
+	for {
+		select {
+		case <-ticker.C:
+			cs.syncJobsFromDB()
+		case <-cs.stopSync:
+			return
+		}
+	}
+}
+
+// syncJobsFromDB diffs the scheduled entries against the cron_jobs table, adding new
+// and changed active jobs and removing those that were deleted or deactivated
+func (cs *cronService) syncJobsFromDB() {
 	cronJobs, err := cs.cronRepository.GetCronJobs()
 	if err != nil {
 		log.Println("Error loading cron jobs from DB:", err)
 		return
 	}
 
+	active := make(map[uint]bool, len(cronJobs))
 	for _, job := range cronJobs {
+		if !job.IsActive {
+			continue
+		}
+		active[job.ID] = true
+
+		cs.mu.Lock()
+		current, exists := cs.jobs[job.ID]
+		rejected, wasRejected := cs.rejected[job.ID]
+		cs.mu.Unlock()
+		if exists && current.fingerprint == jobFingerprint(job) {
+			continue
+		}
+		// Invalid schedules are logged once and skipped until the job is edited
+		if wasRejected && rejected == jobFingerprint(job) {
+			continue
+		}
+
 		cs.scheduleJob(job)
 	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	for id, current := range cs.jobs {
+		if !active[id] {
+			cs.scheduler.Remove(current.entryID)
+			delete(cs.jobs, id)
+			log.Printf("Cron job %d removed from scheduler\n", id)
+		}
+	}
+	for id := range cs.rejected {
+		if !active[id] {
+			delete(cs.rejected, id)
+		}
+	}
+}
+
+// jobFingerprint captures the fields that require the job to be rescheduled when changed
+func jobFingerprint(job model.CronJob) string {
+	return fmt.Sprintf("%s|%s|%s", job.Name, job.Schedule, job.Timezone)
 }
 
 func (cs *cronService) scheduleJob(job model.CronJob) {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
-	if entryID, exists := cs.jobs[job.ID]; exists {
-		cs.scheduler.Remove(entryID)
+	if current, exists := cs.jobs[job.ID]; exists {
+		cs.scheduler.Remove(current.entryID)
+		delete(cs.jobs, job.ID)
 	}
 
-	entryID, err := cs.scheduler.AddFunc(job.Schedule, func() {
-		cs.executeJob(job)
-	})
+	schedule, err := parseSchedule(job)
 	if err != nil {
 		log.Println("Error scheduling job:", err)
+		cs.rejected[job.ID] = jobFingerprint(job)
 		return
 	}
+	delete(cs.rejected, job.ID)
 
-	cs.jobs[job.ID] = entryID
+	jobID := job.ID
+	entryID := cs.scheduler.Schedule(schedule, cron.FuncJob(func() {
+		cs.runJob(jobID)
+	}))
+	cs.jobs[job.ID] = scheduledJob{entryID: entryID, fingerprint: jobFingerprint(job)}
+}
+
+// runJob reloads the job so each run sees the latest state, including deactivation
+// that happened since the last sync
+func (cs *cronService) runJob(id uint) {
+	job, err := cs.cronRepository.GetCronJobByID(id)
+	if err != nil {
+		log.Printf("Error loading cron job %d: %v\n", id, err)
+		return
+	}
+	if !job.IsActive {
+		return
+	}
+
+	cs.executeJob(job)
 }
 
 func (cs *cronService) executeJob(job model.CronJob) {
+	scheduledAt := cs.scheduledTime(job.ID)
+
+	// Every replica fires the same entry; the lease on this run's slot lets only one of them through.
+	// The lease is left to expire rather than released so a replica firing late cannot re-run the slot.
+	lockKey := fmt.Sprintf("cron:%d:%d", job.ID, scheduledAt.Unix())
+	acquired, err := cs.redis.AcquireLock(lockKey, cs.instanceID, cs.lockTTL)
+	if err != nil {
+		log.Printf("Error acquiring lock for job %s: %v\n", job.Name, err)
+		return
+	}
+	if !acquired {
+		cs.recordSkippedRun(job, scheduledAt, lockKey)
+		return
+	}
+
+	fencingToken, err := cs.redis.NextFencingToken(fmt.Sprintf("cron:%d", job.ID))
+	if err != nil {
+		log.Printf("Error issuing fencing token for job %s: %v\n", job.Name, err)
+		return
+	}
+
 	now := time.Now()
+	run := model.CronJobRun{
+		CronJobID:    job.ID,
+		JobName:      job.Name,
+		ScheduledAt:  scheduledAt,
+		Instance:     cs.instanceID,
+		FencingToken: fencingToken,
+		Status:       "running",
+		StartedAt:    now,
+	}
+	if err := cs.cronRepository.CreateCronJobRun(&run); err != nil {
+		log.Println("Error recording cron job run:", err)
+	}
 
 	// Check for missed executions
 	if !job.LastExecutedAt.IsZero() {
-		expectedNextRun := job.LastExecutedAt.Add(cs.getJobInterval(job.Schedule))
-		if now.After(expectedNextRun) {
+		expectedNextRun := cs.nextRunAfter(job, job.LastExecutedAt)
+		if !expectedNextRun.IsZero() && scheduledAt.After(expectedNextRun) {
 			log.Printf("Job %s missed its scheduled run. Executing catch-up.\n", job.Name)
 			// Handle missed execution as needed
 		}
 	}
 
-	// Update the last executed time
-	job.LastExecutedAt = now
-	if err := cs.db.Save(&job).Error; err != nil {
+	// Update the last executed time, rejected if a newer lease holder already wrote it
+	updated, err := cs.cronRepository.UpdateLastExecuted(job.ID, now, fencingToken)
+	if err != nil {
 		log.Println("Error updating job last executed time:", err)
+	} else if !updated {
+		log.Printf("Job %s fencing token %d is stale, skipping last executed update\n", job.Name, fencingToken)
 	}
 
 	// Perform the actual job task
+	var jobErr error
 	switch job.Name {
 	default:
 		log.Printf("Unknown job: %s\n", job.Name)
 	}
+
+	cs.finishRun(&run, jobErr)
+}
+
+// scheduledTime returns the slot the scheduler fired the job for, which is identical across replicas
+func (cs *cronService) scheduledTime(jobID uint) time.Time {
+	cs.mu.Lock()
+	current, exists := cs.jobs[jobID]
+	cs.mu.Unlock()
+
+	if exists {
+		if prev := cs.scheduler.Entry(current.entryID).Prev; !prev.IsZero() {
+			return prev
+		}
+	}
+	return time.Now().Truncate(time.Second)
+}
+
+func (cs *cronService) recordSkippedRun(job model.CronJob, scheduledAt time.Time, lockKey string) {
+	owner, err := cs.redis.GetLockOwner(lockKey)
+	if err != nil {
+		log.Printf("Error reading lock owner for job %s: %v\n", job.Name, err)
+	}
+
+	now := time.Now()
+	reason := fmt.Sprintf("lock held by %s", owner)
+	run := model.CronJobRun{
+		CronJobID:   job.ID,
+		JobName:     job.Name,
+		ScheduledAt: scheduledAt,
+		Instance:    cs.instanceID,
+		Status:      "skipped",
+		Error:       &reason,
+		StartedAt:   now,
+		FinishedAt:  &now,
+	}
+	if err := cs.cronRepository.CreateCronJobRun(&run); err != nil {
+		log.Println("Error recording skipped cron job run:", err)
+	}
+}
+
+func (cs *cronService) finishRun(run *model.CronJobRun, jobErr error) {
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = "success"
+	if jobErr != nil {
+		errMsg := jobErr.Error()
+		run.Status = "failed"
+		run.Error = &errMsg
+	}
+
+	if run.ID == 0 {
+		return
+	}
+	if err := cs.cronRepository.UpdateCronJobRun(run); err != nil {
+		log.Println("Error updating cron job run:", err)
+	}
 }
 
-func (cs *cronService) getJobInterval(schedule string) time.Duration {
-	return time.Minute // Assuming a default interval of 1 minute
+// nextRunAfter returns when the job was due following t, or the zero time if its schedule is invalid
+func (cs *cronService) nextRunAfter(job model.CronJob, t time.Time) time.Time {
+	schedule, err := parseSchedule(job)
+	if err != nil {
+		return time.Time{}
+	}
+	return schedule.Next(t)
 }
 
 func (cs *cronService) AddCronJob(job model.CronJob) {
+	if _, err := parseSchedule(job); err != nil {
+		log.Println("Error creating cron job:", err)
+		return
+	}
+
 	if err := cs.cronRepository.Create(&job); err != nil {
 		log.Println("Error creating cron job:", err)
 		return
 	}
+
+	if job.IsActive {
+		cs.scheduleJob(job)
+	}
 }
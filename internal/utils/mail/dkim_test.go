@@ -0,0 +1,235 @@
+package mail
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	tagSpace      = regexp.MustCompile(`\s+`)
+	bodySpace     = regexp.MustCompile(`[ \t]+`)
+	signatureTail = regexp.MustCompile(`(;\s*b=)[^;]*$`)
+)
+
+func newTestDKIMSigner(t *testing.T) (*DKIMSigner, *rsa.PublicKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	signer, err := NewDKIMSigner("example.com", "mail", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+	return signer, &key.PublicKey
+}
+
+const dkimTestMessage = "From: Sender <sender@example.com>\r\n" +
+	"To: jane@example.org\r\n" +
+	"Subject: A subject long enough\r\n" +
+	"\tto be folded  over two lines\r\n" +
+	"Date: Mon, 19 Oct 2026 10:00:00 +0000\r\n" +
+	"Content-Type: text/plain; charset=UTF-8\r\n" +
+	"X-Unsigned: not in the signed list\r\n" +
+	"\r\n" +
+	"Hello  Jane, \r\n" +
+	"\r\n" +
+	"the report\tis attached.\r\n" +
+	"\r\n" +
+	"\r\n"
+
+func TestDKIMSignVerifies(t *testing.T) {
+	signer, pub := newTestDKIMSigner(t)
+
+	signed, err := signer.Sign([]byte(dkimTestMessage))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if !bytes.HasSuffix(signed, []byte(dkimTestMessage)) {
+		t.Fatal("signing changed the original message")
+	}
+	tags, err := verifyDKIM(signed, pub)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if tags["h"] != "from:to:subject:date:content-type" {
+		t.Errorf("h = %q", tags["h"])
+	}
+	if tags["d"] != "example.com" || tags["s"] != "mail" || tags["c"] != "relaxed/relaxed" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestDKIMSignSurvivesRelaxedChanges(t *testing.T) {
+	signer, pub := newTestDKIMSigner(t)
+	signed, err := signer.Sign([]byte(dkimTestMessage))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// Changes relaxed canonicalization ignores, as relays may make them
+	changes := map[string]func(string) string{
+		"more trailing blank lines": func(m string) string { return m + "\r\n\r\n" },
+		"trailing whitespace":       func(m string) string { return strings.Replace(m, "attached.", "attached.  ", 1) },
+		"refolded header": func(m string) string {
+			return strings.Replace(m, "enough\r\n\tto be", "enough to\r\n be", 1)
+		},
+		"header name case": func(m string) string { return strings.Replace(m, "\r\nTo:", "\r\nTO:", 1) },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			if _, err := verifyDKIM([]byte(change(string(signed))), pub); err != nil {
+				t.Errorf("verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestDKIMSignDetectsTampering(t *testing.T) {
+	signer, pub := newTestDKIMSigner(t)
+	signed, err := signer.Sign([]byte(dkimTestMessage))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	changes := map[string]func(string) string{
+		"body":            func(m string) string { return strings.Replace(m, "the report", "the invoice", 1) },
+		"signed header":   func(m string) string { return strings.Replace(m, "over two lines", "over three lines", 1) },
+		"added To header": func(m string) string { return strings.Replace(m, "\r\n\r\n", "\r\nTo: eve@example.net\r\n\r\n", 1) },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			if _, err := verifyDKIM([]byte(change(string(signed))), pub); err == nil {
+				t.Error("verify succeeded on a tampered message")
+			}
+		})
+	}
+
+	// Unsigned headers may change freely
+	unsigned := strings.Replace(string(signed), "not in the signed list", "changed", 1)
+	if _, err := verifyDKIM([]byte(unsigned), pub); err != nil {
+		t.Errorf("verify after changing an unsigned header: %v", err)
+	}
+}
+
+func TestDKIMSignRequiresFrom(t *testing.T) {
+	signer, _ := newTestDKIMSigner(t)
+	if _, err := signer.Sign([]byte("To: jane@example.org\r\n\r\nbody\r\n")); err == nil {
+		t.Error("signed a message without From")
+	}
+	if _, err := signer.Sign([]byte("From: sender@example.com\r\n")); err == nil {
+		t.Error("signed a message without a header/body separator")
+	}
+}
+
+func TestNewDKIMSignerKeyFormats(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if _, err := NewDKIMSigner("example.com", "mail", pkcs1); err != nil {
+		t.Errorf("PKCS#1 key: %v", err)
+	}
+	if _, err := NewDKIMSigner("example.com", "", pkcs1); err == nil {
+		t.Error("accepted an empty selector")
+	}
+	if _, err := NewDKIMSigner("example.com", "mail", []byte("not pem")); err == nil {
+		t.Error("accepted a key that is not PEM")
+	}
+}
+
+// verifyDKIM checks the first DKIM-Signature of message as a receiving server would, with its
+// own relaxed/relaxed canonicalization, and returns the signature's tags
+func verifyDKIM(message []byte, pub *rsa.PublicKey) (map[string]string, error) {
+	head, body, ok := strings.Cut(string(message), "\r\n\r\n")
+	if !ok {
+		return nil, errors.New("no header/body separator")
+	}
+
+	var fields []string
+	for _, line := range strings.SplitAfter(head+"\r\n", "\r\n") {
+		switch {
+		case line == "":
+		case line[0] == ' ' || line[0] == '\t':
+			fields[len(fields)-1] += line
+		default:
+			fields = append(fields, line)
+		}
+	}
+	if len(fields) == 0 || !strings.HasPrefix(strings.ToLower(fields[0]), "dkim-signature:") {
+		return nil, errors.New("no DKIM-Signature header first")
+	}
+	signature := strings.TrimSuffix(fields[0], "\r\n")
+	fields = fields[1:]
+
+	tags := map[string]string{}
+	_, value, _ := strings.Cut(signature, ":")
+	for _, tag := range strings.Split(value, ";") {
+		name, tagValue, _ := strings.Cut(tag, "=")
+		tags[strings.TrimSpace(name)] = tagSpace.ReplaceAllString(tagValue, "")
+	}
+
+	bodyHash := sha256.Sum256([]byte(canonicalBody(body)))
+	if got := base64.StdEncoding.EncodeToString(bodyHash[:]); got != tags["bh"] {
+		return tags, fmt.Errorf("body hash %s, signature has %s", got, tags["bh"])
+	}
+
+	// Each name in h= takes the bottom-most instance not already used
+	used := map[int]bool{}
+	hash := sha256.New()
+	for _, name := range strings.Split(tags["h"], ":") {
+		for i := len(fields) - 1; i >= 0; i-- {
+			field, _, _ := strings.Cut(fields[i], ":")
+			if !used[i] && strings.EqualFold(strings.TrimSpace(field), name) {
+				used[i] = true
+				hash.Write([]byte(canonicalHeader(fields[i]) + "\r\n"))
+				break
+			}
+		}
+	}
+	emptied := signatureTail.ReplaceAllString(signature, "$1")
+	hash.Write([]byte(canonicalHeader(emptied)))
+
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		return tags, fmt.Errorf("decode b=: %w", err)
+	}
+	return tags, rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash.Sum(nil), sig)
+}
+
+func canonicalHeader(field string) string {
+	name, value, _ := strings.Cut(strings.TrimSuffix(field, "\r\n"), ":")
+	value = strings.NewReplacer("\r\n", "").Replace(value)
+	value = strings.Join(strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == '\t' }), " ")
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + value
+}
+
+func canonicalBody(body string) string {
+	lines := strings.Split(body, "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(bodySpace.ReplaceAllString(line, " "), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
@@ -0,0 +1,100 @@
+package mail
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+)
+
+// entity is one MIME part: its headers and a writer for its encoded body
+type entity struct {
+	header textproto.MIMEHeader
+	write  func(w io.Writer) error
+}
+
+func textPart(contentType, content string) entity {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=UTF-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return entity{header: header, write: func(w io.Writer) error {
+		return writeQuotedPrintable(w, content)
+	}}
+}
+
+// multipartEntity nests parts under a fresh boundary; the boundary is chosen up front because
+// the parent writes the Content-Type header before the body
+func multipartEntity(mediaType string, params map[string]string, parts ...entity) entity {
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	if params == nil {
+		params = map[string]string{}
+	}
+	params["boundary"] = boundary
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	return entity{header: header, write: func(w io.Writer) error {
+		writer := multipart.NewWriter(w)
+		if err := writer.SetBoundary(boundary); err != nil {
+			return err
+		}
+		for _, part := range parts {
+			partWriter, err := writer.CreatePart(part.header)
+			if err != nil {
+				return err
+			}
+			if err := part.write(partWriter); err != nil {
+				return err
+			}
+		}
+		return writer.Close()
+	}}
+}
+
+func inlinePart(attachment Attachment) entity {
+	part := attachment.part()
+	part.header.Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.Filename}))
+	part.header.Set("Content-ID", "<"+attachment.ContentID+">")
+	return part
+}
+
+func (a Attachment) part() entity {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(a.Filename))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(a.Data)
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "application/octet-stream", map[string]string{}
+	}
+	params["name"] = a.Filename
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	header.Set("Content-Transfer-Encoding", "base64")
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+
+	data := a.Data
+	return entity{header: header, write: func(w io.Writer) error {
+		return writeBase64(w, data)
+	}}
+}
+
+// writeBase64 wraps the encoded data at 76 characters as RFC 2045 requires
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := min(76, len(encoded))
+		if _, err := io.WriteString(w, encoded[:n]+crlf); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+const crlf = "\r\n"
+
+// Message is an email before it is encoded; Bytes produces the RFC 5322 wire form
+type Message struct {
+	From    netmail.Address
+	To      []netmail.Address
+	ReplyTo *netmail.Address
+	Subject string
+	HTML    string
+	Text    string // generated from HTML when empty
+
+	// Attachments with a ContentID are embedded inline and referenced from the HTML as cid:<id>
+	Attachments []Attachment
+
+	// ListUnsubscribe holds https: and mailto: URIs; an https one also enables one-click
+	// unsubscribe (RFC 8058)
+	ListUnsubscribe []string
+	Headers         map[string]string // extra headers, e.g. X-Entity-Ref-ID
+
+	Date      time.Time // defaults to now
+	MessageID string    // defaults to a random id on the sender's domain
+}
+
+// Attachment is a file carried by the message
+type Attachment struct {
+	Filename    string
+	ContentType string // detected from the filename or content when empty
+	Data        []byte
+	ContentID   string
+}
+
+// Recipients returns the bare addresses for the SMTP envelope
+func (m *Message) Recipients() []string {
+	addresses := make([]string, 0, len(m.To))
+	for _, to := range m.To {
+		addresses = append(addresses, to.Address)
+	}
+	return addresses
+}
+
+// Bytes encodes the message as multipart/alternative with a plain-text and an HTML part,
+// wrapped in multipart/related for inline images and multipart/mixed for attachments
+func (m *Message) Bytes() ([]byte, error) {
+	if m.From.Address == "" {
+		return nil, errors.New("message has no sender")
+	}
+	if len(m.To) == 0 {
+		return nil, errors.New("message has no recipients")
+	}
+
+	if m.Date.IsZero() {
+		m.Date = time.Now()
+	}
+	if m.MessageID == "" {
+		id, err := NewMessageID(m.From.Address)
+		if err != nil {
+			return nil, err
+		}
+		m.MessageID = id
+	}
+	text := m.Text
+	if text == "" {
+		text = HTMLToText(m.HTML)
+	}
+
+	body := textPart("text/plain", text)
+	if m.HTML != "" {
+		// Clients show the last alternative they understand, so the richest part goes last
+		body = multipartEntity("multipart/alternative", nil, body, textPart("text/html", m.HTML))
+	}
+
+	var inline, attached []entity
+	for _, attachment := range m.Attachments {
+		if attachment.ContentID != "" {
+			inline = append(inline, inlinePart(attachment))
+		} else {
+			attached = append(attached, attachment.part())
+		}
+	}
+	if len(inline) > 0 {
+		params := map[string]string{"type": strings.SplitN(body.header.Get("Content-Type"), ";", 2)[0]}
+		body = multipartEntity("multipart/related", params, append([]entity{body}, inline...)...)
+	}
+	if len(attached) > 0 {
+		body = multipartEntity("multipart/mixed", nil, append([]entity{body}, attached...)...)
+	}
+
+	var buf bytes.Buffer
+	m.writeHeaders(&buf)
+	for _, key := range sortedKeys(body.header) {
+		writeHeader(&buf, key, body.header.Get(key))
+	}
+	buf.WriteString(crlf)
+	if err := body.write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *Message) writeHeaders(buf *bytes.Buffer) {
+	to := make([]string, 0, len(m.To))
+	for _, address := range m.To {
+		to = append(to, address.String())
+	}
+
+	writeHeader(buf, "From", m.From.String())
+	writeHeader(buf, "To", strings.Join(to, ", "))
+	if m.ReplyTo != nil {
+		writeHeader(buf, "Reply-To", m.ReplyTo.String())
+	}
+	writeHeader(buf, "Subject", mime.QEncoding.Encode("UTF-8", m.Subject))
+	writeHeader(buf, "Date", m.Date.Format(time.RFC1123Z))
+	writeHeader(buf, "Message-ID", m.MessageID)
+	writeHeader(buf, "MIME-Version", "1.0")
+
+	if len(m.ListUnsubscribe) > 0 {
+		uris := make([]string, 0, len(m.ListUnsubscribe))
+		oneClick := false
+		for _, uri := range m.ListUnsubscribe {
+			uris = append(uris, "<"+uri+">")
+			oneClick = oneClick || strings.HasPrefix(uri, "https://")
+		}
+		writeHeader(buf, "List-Unsubscribe", strings.Join(uris, ", "))
+		if oneClick {
+			writeHeader(buf, "List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+		}
+	}
+
+	for _, key := range sortedKeys(m.Headers) {
+		writeHeader(buf, textproto.CanonicalMIMEHeaderKey(key), mime.QEncoding.Encode("UTF-8", m.Headers[key]))
+	}
+}
+
+// writeHeader drops CR and LF from values so callers cannot inject headers
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	buf.WriteString(key + ": " + value + crlf)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeQuotedPrintable normalizes line endings to CRLF before encoding
+func writeQuotedPrintable(w io.Writer, content string) error {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\n", crlf)
+
+	qp := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qp, content); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// NewMessageID returns a unique Message-ID on the sender's domain
+func NewMessageID(from string) (string, error) {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("generate message id: %w", err)
+	}
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain), nil
+}
@@ -0,0 +1,136 @@
+package mail
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMessageOverSMTP(t *testing.T) {
+	server := newTestSMTPServer(t, false, nil)
+	transport := NewSMTPTransport(server.pool(t, AuthNone), nil)
+
+	message := &Message{
+		From:            netmail.Address{Name: "My Home", Address: "noreply@example.com"},
+		To:              []netmail.Address{{Name: "Jane", Address: "jane@example.org"}},
+		Subject:         "Atur ulang kata sandi — ñ",
+		HTML:            "<p>Hello\nJane</p>\n<p><a href=\"https://example.com/reset\">Reset</a></p>",
+		ListUnsubscribe: []string{"https://example.com/unsubscribe?t=1", "mailto:unsubscribe@example.com"},
+	}
+	if err := transport.Send(message); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	received := server.received()
+	if len(received) != 1 {
+		t.Fatalf("received %d messages, want 1", len(received))
+	}
+	got := received[0]
+	if got.from != "noreply@example.com" || len(got.to) != 1 || got.to[0] != "jane@example.org" {
+		t.Fatalf("envelope = %s -> %v", got.from, got.to)
+	}
+
+	if bare := regexp.MustCompile(`[^\r]\n`).Find(got.data); bare != nil {
+		t.Fatalf("message has a bare LF: %q", bare)
+	}
+
+	parsed, err := netmail.ReadMessage(bytes.NewReader(got.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	header := parsed.Header
+
+	rawSubject := header.Get("Subject")
+	if !strings.HasPrefix(rawSubject, "=?UTF-8?q?") {
+		t.Errorf("Subject = %q, want Q-encoded UTF-8", rawSubject)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(rawSubject)
+	if err != nil || subject != message.Subject {
+		t.Errorf("decoded Subject = %q (%v), want %q", subject, err, message.Subject)
+	}
+
+	if _, err := header.Date(); err != nil {
+		t.Errorf("Date %q does not parse: %v", header.Get("Date"), err)
+	}
+	if id := header.Get("Message-ID"); !regexp.MustCompile(`^<[^<>@]+@example\.com>$`).MatchString(id) {
+		t.Errorf("Message-ID = %q", id)
+	}
+	if got, want := header.Get("List-Unsubscribe"), "<https://example.com/unsubscribe?t=1>, <mailto:unsubscribe@example.com>"; got != want {
+		t.Errorf("List-Unsubscribe = %q, want %q", got, want)
+	}
+	if got := header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q", got)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/alternative", header.Get("Content-Type"), err)
+	}
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var parts []string
+	var bodies []string
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		if part.Header.Get("Content-Transfer-Encoding") != "quoted-printable" {
+			t.Errorf("part %s is not quoted-printable", part.Header.Get("Content-Type"))
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatalf("decode part: %v", err)
+		}
+		parts = append(parts, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+
+	want := []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"}
+	if strings.Join(parts, "|") != strings.Join(want, "|") {
+		t.Fatalf("parts = %v, want %v", parts, want)
+	}
+	if !strings.Contains(bodies[0], "Reset (https://example.com/reset)") {
+		t.Errorf("text part = %q", bodies[0])
+	}
+	if !strings.Contains(bodies[1], "<a href=\"https://example.com/reset\">Reset</a>") {
+		t.Errorf("html part = %q", bodies[1])
+	}
+}
+
+func TestMessageListUnsubscribeMailtoOnly(t *testing.T) {
+	message := &Message{
+		From:            netmail.Address{Address: "noreply@example.com"},
+		To:              []netmail.Address{{Address: "jane@example.org"}},
+		Subject:         "Hello",
+		Text:            "Hello",
+		ListUnsubscribe: []string{"mailto:unsubscribe@example.com"},
+	}
+	body, err := message.Bytes()
+	if err != nil {
+		t.Fatalf("bytes: %v", err)
+	}
+
+	parsed, err := netmail.ReadMessage(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	if got := parsed.Header.Get("List-Unsubscribe"); got != "<mailto:unsubscribe@example.com>" {
+		t.Errorf("List-Unsubscribe = %q", got)
+	}
+	if got := parsed.Header.Get("List-Unsubscribe-Post"); got != "" {
+		t.Errorf("List-Unsubscribe-Post = %q, want none without an https URI", got)
+	}
+	if got := parsed.Header.Get("Content-Type"); got != "text/plain; charset=UTF-8" {
+		t.Errorf("Content-Type = %q, want a single text part", got)
+	}
+}
@@ -0,0 +1,283 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTP API providers selectable through HTTPConfig.Provider
+const (
+	ProviderSendGrid = "sendgrid" // SendGrid v3 mail/send JSON
+	ProviderMailgun  = "mailgun"  // Mailgun messages.mime with the raw, DKIM-signed MIME message
+	ProviderJSON     = "json"     // generic JSON body for in-house gateways and SES-style relays
+)
+
+const defaultSendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+type HTTPConfig struct {
+	Provider string
+	Endpoint string // required for mailgun (https://api.mailgun.net/v3/<domain>/messages.mime) and json
+	APIKey   string
+	Timeout  time.Duration // defaults to 30s
+}
+
+type httpTransport struct {
+	cfg    HTTPConfig
+	client *http.Client
+	dkim   *DKIMSigner
+}
+
+// NewHTTPTransport sends through a provider API; dkim only applies to providers that take raw MIME
+func NewHTTPTransport(cfg HTTPConfig, dkim *DKIMSigner) (Transport, error) {
+	switch cfg.Provider {
+	case ProviderSendGrid:
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = defaultSendGridEndpoint
+		}
+	case ProviderMailgun, ProviderJSON:
+		if cfg.Endpoint == "" {
+			return nil, fmt.Errorf("%s provider needs an endpoint", cfg.Provider)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported email provider: %s", cfg.Provider)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	return &httpTransport{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}, dkim: dkim}, nil
+}
+
+func (t *httpTransport) Send(message *Message) error {
+	var req *http.Request
+	var err error
+	switch t.cfg.Provider {
+	case ProviderSendGrid:
+		req, err = t.jsonRequest(sendGridBody(message))
+	case ProviderMailgun:
+		req, err = t.mailgunRequest(message)
+	default:
+		req, err = t.jsonRequest(jsonBody(message))
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request: %w", t.cfg.Provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	err = fmt.Errorf("%s responded %s: %s", t.cfg.Provider, resp.Status, strings.TrimSpace(string(detail)))
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return fmt.Errorf("%w: %w", ErrRejected, err)
+	default:
+		// Auth, rate limit and server errors can all clear up, so they stay retryable
+		return err
+	}
+}
+
+func (t *httpTransport) jsonRequest(body interface{}, err error) (*http.Request, error) {
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.cfg.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+t.cfg.APIKey)
+	return req, nil
+}
+
+func (t *httpTransport) mailgunRequest(message *Message) (*http.Request, error) {
+	raw, err := encode(message, t.dkim)
+	if err != nil {
+		return nil, err
+	}
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	for _, to := range message.Recipients() {
+		if err := writer.WriteField("to", to); err != nil {
+			return nil, err
+		}
+	}
+	part, err := writer.CreateFormFile("message", "message.mime")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.cfg.Endpoint, &form)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.SetBasicAuth("api", t.cfg.APIKey)
+	return req, nil
+}
+
+// providerHeaders lists the headers the providers do not set on their own
+func providerHeaders(message *Message) map[string]string {
+	headers := make(map[string]string, len(message.Headers)+2)
+	for key, value := range message.Headers {
+		headers[key] = value
+	}
+	if len(message.ListUnsubscribe) > 0 {
+		uris := make([]string, 0, len(message.ListUnsubscribe))
+		for _, uri := range message.ListUnsubscribe {
+			uris = append(uris, "<"+uri+">")
+			if strings.HasPrefix(uri, "https://") {
+				headers["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
+			}
+		}
+		headers["List-Unsubscribe"] = strings.Join(uris, ", ")
+	}
+	return headers
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Filename    string `json:"filename"`
+	Type        string `json:"type,omitempty"`
+	Disposition string `json:"disposition"`
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+func sendGridBody(message *Message) (interface{}, error) {
+	if len(message.To) == 0 {
+		return nil, errors.New("message has no recipients")
+	}
+
+	to := make([]sendGridAddress, 0, len(message.To))
+	for _, address := range message.To {
+		to = append(to, sendGridAddress{Email: address.Address, Name: address.Name})
+	}
+
+	text := message.Text
+	if text == "" {
+		text = HTMLToText(message.HTML)
+	}
+	content := []sendGridContent{{Type: "text/plain", Value: text}}
+	if message.HTML != "" {
+		content = append(content, sendGridContent{Type: "text/html", Value: message.HTML})
+	}
+
+	attachments := make([]sendGridAttachment, 0, len(message.Attachments))
+	for _, attachment := range message.Attachments {
+		disposition := "attachment"
+		if attachment.ContentID != "" {
+			disposition = "inline"
+		}
+		attachments = append(attachments, sendGridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(attachment.Data),
+			Filename:    attachment.Filename,
+			Type:        attachment.ContentType,
+			Disposition: disposition,
+			ContentID:   attachment.ContentID,
+		})
+	}
+
+	body := map[string]interface{}{
+		"personalizations": []map[string]interface{}{{"to": to}},
+		"from":             sendGridAddress{Email: message.From.Address, Name: message.From.Name},
+		"subject":          message.Subject,
+		"content":          content,
+	}
+	if message.ReplyTo != nil {
+		body["reply_to"] = sendGridAddress{Email: message.ReplyTo.Address, Name: message.ReplyTo.Name}
+	}
+	if len(attachments) > 0 {
+		body["attachments"] = attachments
+	}
+	if headers := providerHeaders(message); len(headers) > 0 {
+		body["headers"] = headers
+	}
+	return body, nil
+}
+
+type jsonAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Content     string `json:"content"` // base64
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+type jsonMessage struct {
+	From        string            `json:"from"`
+	To          []string          `json:"to"`
+	ReplyTo     string            `json:"reply_to,omitempty"`
+	Subject     string            `json:"subject"`
+	HTML        string            `json:"html,omitempty"`
+	Text        string            `json:"text"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Attachments []jsonAttachment  `json:"attachments,omitempty"`
+}
+
+func jsonBody(message *Message) (interface{}, error) {
+	if len(message.To) == 0 {
+		return nil, errors.New("message has no recipients")
+	}
+
+	body := jsonMessage{
+		From:    message.From.String(),
+		Subject: message.Subject,
+		HTML:    message.HTML,
+		Text:    message.Text,
+		Headers: providerHeaders(message),
+	}
+	if body.Text == "" {
+		body.Text = HTMLToText(message.HTML)
+	}
+	for _, to := range message.To {
+		body.To = append(body.To, to.String())
+	}
+	if message.ReplyTo != nil {
+		body.ReplyTo = message.ReplyTo.String()
+	}
+	for _, attachment := range message.Attachments {
+		body.Attachments = append(body.Attachments, jsonAttachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Content:     base64.StdEncoding.EncodeToString(attachment.Data),
+			ContentID:   attachment.ContentID,
+		})
+	}
+	return body, nil
+}
@@ -0,0 +1,213 @@
+package mail
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	netmail "net/mail"
+	"strings"
+	"testing"
+)
+
+func testProviderMessage() *Message {
+	return &Message{
+		From:            netmail.Address{Name: "My Home", Address: "noreply@example.com"},
+		To:              []netmail.Address{{Name: "Jane", Address: "jane@example.org"}},
+		Subject:         "Your report",
+		HTML:            "<p>Hello Jane</p><img src=\"cid:logo\">",
+		Attachments:     []Attachment{{Filename: "logo.png", ContentType: "image/png", Data: []byte("png"), ContentID: "logo"}},
+		ListUnsubscribe: []string{"https://example.com/unsubscribe?t=1"},
+		Headers:         map[string]string{"X-Entity-Ref-ID": "42"},
+	}
+}
+
+// testProviderServer records the one request it receives and answers with status
+func testProviderServer(t *testing.T, status int) (*httptest.Server, *http.Request, *[]byte) {
+	t.Helper()
+	var received http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = *r.Clone(r.Context())
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"message":"test"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &received, &body
+}
+
+func TestSendGridTransport(t *testing.T) {
+	server, req, body := testProviderServer(t, http.StatusAccepted)
+	transport, err := NewHTTPTransport(HTTPConfig{Provider: ProviderSendGrid, Endpoint: server.URL, APIKey: "key"}, nil)
+	if err != nil {
+		t.Fatalf("new transport: %v", err)
+	}
+	if err := transport.Send(testProviderMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if got := req.Header.Get("Authorization"); got != "Bearer key" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	var payload struct {
+		Personalizations []struct {
+			To []sendGridAddress `json:"to"`
+		} `json:"personalizations"`
+		From        sendGridAddress      `json:"from"`
+		Subject     string               `json:"subject"`
+		Content     []sendGridContent    `json:"content"`
+		Attachments []sendGridAttachment `json:"attachments"`
+		Headers     map[string]string    `json:"headers"`
+	}
+	if err := json.Unmarshal(*body, &payload); err != nil {
+		t.Fatalf("decode body %s: %v", *body, err)
+	}
+	if len(payload.Personalizations) != 1 || len(payload.Personalizations[0].To) != 1 ||
+		payload.Personalizations[0].To[0] != (sendGridAddress{Email: "jane@example.org", Name: "Jane"}) {
+		t.Errorf("personalizations = %+v", payload.Personalizations)
+	}
+	if payload.From != (sendGridAddress{Email: "noreply@example.com", Name: "My Home"}) || payload.Subject != "Your report" {
+		t.Errorf("from = %+v, subject = %q", payload.From, payload.Subject)
+	}
+	if len(payload.Content) != 2 || payload.Content[0].Type != "text/plain" || payload.Content[1].Type != "text/html" ||
+		!strings.Contains(payload.Content[0].Value, "Hello Jane") {
+		t.Errorf("content = %+v", payload.Content)
+	}
+	want := sendGridAttachment{Content: base64.StdEncoding.EncodeToString([]byte("png")), Filename: "logo.png", Type: "image/png", Disposition: "inline", ContentID: "logo"}
+	if len(payload.Attachments) != 1 || payload.Attachments[0] != want {
+		t.Errorf("attachments = %+v", payload.Attachments)
+	}
+	if payload.Headers["List-Unsubscribe"] != "<https://example.com/unsubscribe?t=1>" ||
+		payload.Headers["List-Unsubscribe-Post"] != "List-Unsubscribe=One-Click" || payload.Headers["X-Entity-Ref-ID"] != "42" {
+		t.Errorf("headers = %v", payload.Headers)
+	}
+}
+
+func TestJSONTransport(t *testing.T) {
+	server, req, body := testProviderServer(t, http.StatusOK)
+	transport, err := NewHTTPTransport(HTTPConfig{Provider: ProviderJSON, Endpoint: server.URL, APIKey: "key"}, nil)
+	if err != nil {
+		t.Fatalf("new transport: %v", err)
+	}
+	if err := transport.Send(testProviderMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if got := req.Header.Get("Authorization"); got != "Bearer key" {
+		t.Errorf("Authorization = %q", got)
+	}
+	var payload jsonMessage
+	if err := json.Unmarshal(*body, &payload); err != nil {
+		t.Fatalf("decode body %s: %v", *body, err)
+	}
+	if payload.From != `"My Home" <noreply@example.com>` || len(payload.To) != 1 || payload.To[0] != `"Jane" <jane@example.org>` {
+		t.Errorf("from = %q, to = %q", payload.From, payload.To)
+	}
+	if payload.HTML == "" || !strings.Contains(payload.Text, "Hello Jane") {
+		t.Errorf("html = %q, text = %q", payload.HTML, payload.Text)
+	}
+	want := jsonAttachment{Filename: "logo.png", ContentType: "image/png", Content: base64.StdEncoding.EncodeToString([]byte("png")), ContentID: "logo"}
+	if len(payload.Attachments) != 1 || payload.Attachments[0] != want {
+		t.Errorf("attachments = %+v", payload.Attachments)
+	}
+	if payload.Headers["List-Unsubscribe-Post"] != "List-Unsubscribe=One-Click" {
+		t.Errorf("headers = %v", payload.Headers)
+	}
+}
+
+func TestMailgunTransport(t *testing.T) {
+	signer, pub := newTestDKIMSigner(t)
+	var user, password string
+	var to []string
+	var mime []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ = r.BasicAuth()
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		to = r.MultipartForm.Value["to"]
+		file, _, err := r.FormFile("message")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mime, _ = io.ReadAll(file)
+		w.Write([]byte(`{"id":"<1@example.com>"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	transport, err := NewHTTPTransport(HTTPConfig{Provider: ProviderMailgun, Endpoint: server.URL, APIKey: "key"}, signer)
+	if err != nil {
+		t.Fatalf("new transport: %v", err)
+	}
+	if err := transport.Send(testProviderMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if user != "api" || password != "key" {
+		t.Errorf("basic auth = %q:%q", user, password)
+	}
+	if len(to) != 1 || to[0] != "jane@example.org" {
+		t.Errorf("to = %q", to)
+	}
+	if _, err := verifyDKIM(mime, pub); err != nil {
+		t.Errorf("MIME message does not verify: %v", err)
+	}
+	parsed, err := netmail.ReadMessage(strings.NewReader(string(mime)))
+	if err != nil {
+		t.Fatalf("parse MIME message: %v", err)
+	}
+	if parsed.Header.Get("Subject") != "Your report" || parsed.Header.Get("X-Entity-Ref-ID") != "42" {
+		t.Errorf("headers = %v", parsed.Header)
+	}
+}
+
+func TestHTTPTransportStatus(t *testing.T) {
+	tests := []struct {
+		status   int
+		rejected bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusRequestEntityTooLarge, true},
+		{http.StatusUnprocessableEntity, true},
+		{http.StatusUnauthorized, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusServiceUnavailable, false},
+	}
+	for _, provider := range []string{ProviderSendGrid, ProviderMailgun, ProviderJSON} {
+		for _, tt := range tests {
+			t.Run(provider+"/"+http.StatusText(tt.status), func(t *testing.T) {
+				server, _, _ := testProviderServer(t, tt.status)
+				transport, err := NewHTTPTransport(HTTPConfig{Provider: provider, Endpoint: server.URL, APIKey: "key"}, nil)
+				if err != nil {
+					t.Fatalf("new transport: %v", err)
+				}
+				err = transport.Send(testProviderMessage())
+				if err == nil {
+					t.Fatal("send succeeded")
+				}
+				if errors.Is(err, ErrRejected) != tt.rejected {
+					t.Errorf("errors.Is(%v, ErrRejected) = %v, want %v", err, !tt.rejected, tt.rejected)
+				}
+			})
+		}
+	}
+}
+
+func TestNewHTTPTransportConfig(t *testing.T) {
+	if _, err := NewHTTPTransport(HTTPConfig{Provider: ProviderMailgun}, nil); err == nil {
+		t.Error("mailgun without an endpoint was accepted")
+	}
+	if _, err := NewHTTPTransport(HTTPConfig{Provider: "postmark", Endpoint: "https://example.com"}, nil); err == nil {
+		t.Error("unknown provider was accepted")
+	}
+}
@@ -0,0 +1,170 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// Report is one recipient's entry in a delivery status notification (RFC 3464) or an abuse
+// feedback report (RFC 5965)
+type Report struct {
+	Recipient    string
+	Action       string // DSN action: "failed", "delayed", "delivered", ...
+	Status       string // enhanced status code, e.g. "5.1.1"
+	Diagnostic   string
+	FeedbackType string // set for feedback reports, e.g. "abuse"
+	MessageID    string // Message-ID of the original message, when the report includes it
+}
+
+// Complaint tells whether the report is a recipient complaint rather than a delivery status
+func (r Report) Complaint() bool {
+	return r.FeedbackType != ""
+}
+
+// ParseReport reads a multipart/report message, e.g. a bounce piped from the return-path mailbox
+func ParseReport(raw []byte) ([]Report, error) {
+	msg, err := netmail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("read report: %w", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("parse report content type: %w", err)
+	}
+	if mediaType != "multipart/report" {
+		return nil, fmt.Errorf("not a report: %s", mediaType)
+	}
+
+	var reports []Report
+	var original textproto.MIMEHeader
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read report part: %w", err)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			found, err := parseDeliveryStatus(part)
+			if err != nil {
+				return nil, err
+			}
+			reports = append(reports, found...)
+		case "message/feedback-report":
+			found, err := parseFeedbackReport(part)
+			if err != nil {
+				return nil, err
+			}
+			reports = append(reports, found)
+		case "message/rfc822", "text/rfc822-headers", "message/global", "message/global-headers":
+			if original, err = readFieldBlock(bufio.NewReader(part)); err != nil && !errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("read original message: %w", err)
+			}
+		}
+	}
+
+	for i := range reports {
+		if original == nil {
+			break
+		}
+		reports[i].MessageID = original.Get("Message-Id")
+		if reports[i].Recipient == "" {
+			if to, err := netmail.ParseAddress(original.Get("To")); err == nil {
+				reports[i].Recipient = to.Address
+			}
+		}
+	}
+	if len(reports) == 0 {
+		return nil, errors.New("report has no delivery status or feedback part")
+	}
+	return reports, nil
+}
+
+// parseDeliveryStatus skips the per-message block and returns one report per recipient block
+func parseDeliveryStatus(r io.Reader) ([]Report, error) {
+	reader := bufio.NewReader(r)
+	if _, err := readFieldBlock(reader); err != nil {
+		return nil, fmt.Errorf("read delivery status: %w", err)
+	}
+
+	var reports []Report
+	for {
+		fields, err := readFieldBlock(reader)
+		if len(fields) > 0 {
+			reports = append(reports, Report{
+				Recipient:  addressField(fields.Get("Final-Recipient")),
+				Action:     strings.ToLower(strings.TrimSpace(fields.Get("Action"))),
+				Status:     firstWord(fields.Get("Status")),
+				Diagnostic: addressField(fields.Get("Diagnostic-Code")),
+			})
+		}
+		if errors.Is(err, io.EOF) {
+			return reports, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read delivery status: %w", err)
+		}
+	}
+}
+
+func parseFeedbackReport(r io.Reader) (Report, error) {
+	fields, err := readFieldBlock(bufio.NewReader(r))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Report{}, fmt.Errorf("read feedback report: %w", err)
+	}
+
+	feedbackType := strings.ToLower(strings.TrimSpace(fields.Get("Feedback-Type")))
+	if feedbackType == "" {
+		feedbackType = "abuse"
+	}
+	return Report{
+		Recipient:    strings.Trim(strings.TrimSpace(fields.Get("Original-Rcpt-To")), "<>"),
+		FeedbackType: feedbackType,
+	}, nil
+}
+
+// readFieldBlock reads header-style fields up to the next blank line, skipping leading blank lines
+func readFieldBlock(reader *bufio.Reader) (textproto.MIMEHeader, error) {
+	for {
+		peek, err := reader.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if peek[0] != '\r' && peek[0] != '\n' {
+			break
+		}
+		if _, err := reader.ReadByte(); err != nil {
+			return nil, err
+		}
+	}
+	return textproto.NewReader(reader).ReadMIMEHeader()
+}
+
+func firstWord(value string) string {
+	if words := strings.Fields(value); len(words) > 0 {
+		return words[0]
+	}
+	return ""
+}
+
+// addressField strips the type prefix of fields like "rfc822; jane@example.com"
+func addressField(value string) string {
+	if _, rest, ok := strings.Cut(value, ";"); ok {
+		value = rest
+	}
+	return strings.TrimSpace(value)
+}
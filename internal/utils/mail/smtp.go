@@ -0,0 +1,280 @@
+package mail
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"os"
+	"syscall"
+	"time"
+)
+
+// SMTP connection security selectable through SMTPConfig.Security
+const (
+	SecurityStartTLS = "starttls" // plain connection upgraded with STARTTLS, usually port 587
+	SecurityTLS      = "tls"      // implicit TLS from the first byte, usually port 465
+	SecurityNone     = "none"     // local relays and test servers only
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Auth     string // see the Auth* constants; defaults to plain
+	Security string // see the Security* constants; defaults to starttls
+
+	InsecureSkipVerify bool   // accept any certificate, for relays with self-signed ones
+	CAFile             string // PEM bundle trusted in addition to the system roots
+	LocalName          string // name sent in EHLO; defaults to localhost
+
+	PoolSize    int           // maximum open connections; defaults to 4
+	DialTimeout time.Duration // defaults to 10s
+	Timeout     time.Duration // deadline for each message's whole SMTP exchange; defaults to 30s
+	IdleTimeout time.Duration // idle connections older than this are closed instead of reused; defaults to 1m
+}
+
+// SMTPPool reuses authenticated SMTP connections across messages. At most PoolSize
+// connections are open at once; callers beyond that wait for a free one.
+type SMTPPool struct {
+	cfg       SMTPConfig
+	auth      smtp.Auth
+	tlsConfig *tls.Config
+	slots     chan struct{}
+	idle      chan *smtpConn
+}
+
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+func NewSMTPPool(cfg SMTPConfig) (*SMTPPool, error) {
+	if cfg.Security == "" {
+		cfg.Security = SecurityStartTLS
+	}
+	if cfg.Security != SecurityStartTLS && cfg.Security != SecurityTLS && cfg.Security != SecurityNone {
+		return nil, fmt.Errorf("unsupported smtp security: %s", cfg.Security)
+	}
+	if cfg.LocalName == "" {
+		cfg.LocalName = "localhost"
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 4
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 10 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = time.Minute
+	}
+
+	auth, err := newAuth(cfg.Auth, cfg.Host, cfg.Username, cfg.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.InsecureSkipVerify, MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read smtp ca file: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	return &SMTPPool{
+		cfg:       cfg,
+		auth:      auth,
+		tlsConfig: tlsConfig,
+		slots:     make(chan struct{}, cfg.PoolSize),
+		idle:      make(chan *smtpConn, cfg.PoolSize),
+	}, nil
+}
+
+// Send delivers one message. A reused connection that turns out to be dead is replaced by a
+// fresh one once; the relay may have dropped it while idle.
+func (p *SMTPPool) Send(from string, to []string, message []byte) error {
+	p.slots <- struct{}{}
+	defer func() { <-p.slots }()
+
+	c, reused, err := p.get()
+	if err != nil {
+		return err
+	}
+
+	err = p.send(c, from, to, message)
+	if err != nil && reused && isConnectionError(err) {
+		c.close()
+		if c, err = p.dial(); err != nil {
+			return err
+		}
+		err = p.send(c, from, to, message)
+	}
+	if err != nil {
+		c.close()
+		return err
+	}
+
+	c.lastUsed = time.Now()
+	p.idle <- c
+	return nil
+}
+
+// Close quits every idle connection
+func (p *SMTPPool) Close() error {
+	var errs []error
+	for {
+		select {
+		case c := <-p.idle:
+			if err := c.client.Quit(); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// get takes the longest idle connection, since the idle channel is FIFO, dialing when none is
+// fresh enough
+func (p *SMTPPool) get() (*smtpConn, bool, error) {
+	for {
+		select {
+		case c := <-p.idle:
+			if time.Since(c.lastUsed) > p.cfg.IdleTimeout {
+				c.close()
+				continue
+			}
+			return c, true, nil
+		default:
+			c, err := p.dial()
+			return c, false, err
+		}
+	}
+}
+
+func (p *SMTPPool) dial() (*smtpConn, error) {
+	address := net.JoinHostPort(p.cfg.Host, p.cfg.Port)
+	dialer := &net.Dialer{Timeout: p.cfg.DialTimeout}
+
+	var conn net.Conn
+	var err error
+	if p.cfg.Security == SecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, p.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dial smtp %s: %w", address, err)
+	}
+
+	// The handshake gets the same deadline as a message exchange
+	if err := conn.SetDeadline(time.Now().Add(p.cfg.Timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, p.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp greeting: %w", err)
+	}
+	c := &smtpConn{conn: conn, client: client}
+
+	if err := client.Hello(p.cfg.LocalName); err != nil {
+		c.close()
+		return nil, fmt.Errorf("smtp hello: %w", err)
+	}
+
+	if p.cfg.Security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			c.close()
+			return nil, errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(p.tlsConfig); err != nil {
+			c.close()
+			return nil, fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+
+	if p.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			c.close()
+			return nil, errors.New("smtp server does not support AUTH")
+		}
+		if err := client.Auth(p.auth); err != nil {
+			c.close()
+			return nil, fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// send resets a reused connection so no envelope state leaks between messages
+func (p *SMTPPool) send(c *smtpConn, from string, to []string, message []byte) error {
+	if err := c.conn.SetDeadline(time.Now().Add(p.cfg.Timeout)); err != nil {
+		return err
+	}
+	if err := c.client.Reset(); err != nil {
+		return fmt.Errorf("smtp reset: %w", err)
+	}
+
+	if err := c.client.Mail(from); err != nil {
+		return &envelopeError{fmt.Errorf("smtp mail from: %w", err)}
+	}
+	for _, recipient := range to {
+		if err := c.client.Rcpt(recipient); err != nil {
+			return &envelopeError{fmt.Errorf("smtp rcpt to %s: %w", recipient, err)}
+		}
+	}
+
+	w, err := c.client.Data()
+	if err != nil {
+		return &envelopeError{fmt.Errorf("smtp data: %w", err)}
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("smtp write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return &envelopeError{fmt.Errorf("smtp data end: %w", err)}
+	}
+	return nil
+}
+
+// envelopeError wraps the outcome of MAIL FROM, RCPT TO and DATA, the commands whose replies
+// are about this message rather than the session, unlike the greeting or AUTH
+type envelopeError struct {
+	err error
+}
+
+func (e *envelopeError) Error() string { return e.err.Error() }
+
+func (e *envelopeError) Unwrap() error { return e.err }
+
+func (c *smtpConn) close() {
+	c.client.Close()
+}
+
+// isConnectionError tells a dropped connection apart from an SMTP error reply such as a
+// rejected recipient, which a new connection would not fix
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,148 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testSMTPServer is a minimal in-process SMTP server. Commands answer 250 unless replies
+// overrides them by verb, e.g. "RCPT": "550 5.1.1 no such user"; "." overrides the reply to
+// the end of the message data.
+type testSMTPServer struct {
+	listener net.Listener
+	auth     bool
+	replies  map[string]string
+
+	mu       sync.Mutex
+	messages []receivedMessage
+}
+
+type receivedMessage struct {
+	from string
+	to   []string
+	data []byte // as received, with dot-stuffing undone
+}
+
+func newTestSMTPServer(t *testing.T, auth bool, replies map[string]string) *testSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &testSMTPServer{listener: listener, auth: auth, replies: replies}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *testSMTPServer) pool(t *testing.T, mechanism string) *SMTPPool {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	pool, err := NewSMTPPool(SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: "user",
+		Password: "secret",
+		Auth:     mechanism,
+		Security: SecurityNone,
+	})
+	if err != nil {
+		t.Fatalf("new pool: %v", err)
+	}
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+func (s *testSMTPServer) received() []receivedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMessage(nil), s.messages...)
+}
+
+func (s *testSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP test")
+	var current receivedMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		if custom, ok := s.replies[verb]; ok {
+			reply(custom)
+			continue
+		}
+
+		switch verb {
+		case "EHLO", "HELO":
+			if s.auth {
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			} else {
+				reply("250 localhost")
+			}
+		case "AUTH":
+			reply("235 2.7.0 authenticated")
+		case "MAIL":
+			current = receivedMessage{from: envelopeAddress(line)}
+			reply("250 OK")
+		case "RCPT":
+			current.to = append(current.to, envelopeAddress(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var data bytes.Buffer
+			for {
+				chunk, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if chunk == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(chunk, "."))
+			}
+			if custom, ok := s.replies["."]; ok {
+				reply(custom)
+				continue
+			}
+			current.data = data.Bytes()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func envelopeAddress(line string) string {
+	start, end := strings.Index(line, "<"), strings.LastIndex(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	invisibleElements = regexp.MustCompile(`(?is)<(head|style|script|title)\b.*?</(head|style|script|title)>`)
+	links             = regexp.MustCompile(`(?is)<a\b[^>]*?href\s*=\s*["']([^"']+)["'][^>]*>(.*?)</a>`)
+	lineBreaks        = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr|table|blockquote)>`)
+	cellBreaks        = regexp.MustCompile(`(?i)</(td|th)>`)
+	listItems         = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	tags              = regexp.MustCompile(`(?s)<[^>]*>`)
+	whitespace        = regexp.MustCompile(`\s+`)
+	spaces            = regexp.MustCompile(` +`)
+	blankLines        = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText derives the plain-text alternative from an HTML body: links keep their URL,
+// block elements become line breaks and table cells are separated by tabs
+func HTMLToText(body string) string {
+	// Source line breaks are plain whitespace in HTML; only markup decides where lines end
+	text := invisibleElements.ReplaceAllString(body, "")
+	text = whitespace.ReplaceAllString(text, " ")
+	text = links.ReplaceAllStringFunc(text, func(link string) string {
+		match := links.FindStringSubmatch(link)
+		label := strings.TrimSpace(tags.ReplaceAllString(match[2], ""))
+		if label == "" || label == match[1] {
+			return match[1]
+		}
+		return label + " (" + match[1] + ")"
+	})
+	text = listItems.ReplaceAllString(text, "- ")
+	text = lineBreaks.ReplaceAllString(text, "\n")
+	text = cellBreaks.ReplaceAllString(text, "\t")
+	text = tags.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = spaces.ReplaceAllString(line, " ")
+		line = strings.NewReplacer(" \t", "\t", "\t ", "\t").Replace(line)
+		lines[i] = strings.Trim(line, " \t")
+	}
+	text = strings.Join(lines, "\n")
+	return strings.TrimSpace(blankLines.ReplaceAllString(text, "\n\n"))
+}
@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	anchorTags  = regexp.MustCompile(`(?is)<a\b[^>]*>`)
+	anchorHref  = regexp.MustCompile(`(?is)(\shref\s*=\s*)("[^"]*"|'[^']*')`)
+	noTrack     = regexp.MustCompile(`(?i)\sdata-no-track\b`)
+	closingBody = regexp.MustCompile(`(?i)</body\s*>`)
+)
+
+// RewriteLinks replaces the href of every http(s) link with rewrite(href). Anchors marked
+// data-no-track, such as unsubscribe links, and mailto:, tel: or fragment links are kept.
+func RewriteLinks(body string, rewrite func(href string) string) string {
+	return anchorTags.ReplaceAllStringFunc(body, func(tag string) string {
+		if noTrack.MatchString(tag) {
+			return tag
+		}
+		match := anchorHref.FindStringSubmatchIndex(tag)
+		if match == nil {
+			return tag
+		}
+		quoted := tag[match[4]:match[5]]
+		href := html.UnescapeString(strings.TrimSpace(quoted[1 : len(quoted)-1]))
+		lower := strings.ToLower(href)
+		if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+			return tag
+		}
+		return tag[:match[4]] + `"` + html.EscapeString(rewrite(href)) + `"` + tag[match[5]:]
+	})
+}
+
+// AppendPixel adds a 1x1 image before </body>, or at the end when the body has no closing tag
+func AppendPixel(body, src string) string {
+	pixel := `<img src="` + html.EscapeString(src) + `" width="1" height="1" alt="" style="display:block;width:1px;height:1px;border:0;">`
+	if loc := closingBody.FindAllStringIndex(body, -1); len(loc) > 0 {
+		last := loc[len(loc)-1]
+		return body[:last[0]] + pixel + body[last[0]:]
+	}
+	return body + pixel
+}
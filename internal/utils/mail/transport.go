@@ -0,0 +1,62 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+)
+
+// ErrRejected marks messages the transport refused outright; sending them again will not help
+var ErrRejected = errors.New("message rejected")
+
+// Transport delivers a message over SMTP or a provider's HTTP API
+type Transport interface {
+	Send(message *Message) error
+}
+
+type smtpTransport struct {
+	pool *SMTPPool
+	dkim *DKIMSigner
+}
+
+// NewSMTPTransport sends through the pool, DKIM-signing first when dkim is not nil
+func NewSMTPTransport(pool *SMTPPool, dkim *DKIMSigner) Transport {
+	return &smtpTransport{pool: pool, dkim: dkim}
+}
+
+func (t *smtpTransport) Send(message *Message) error {
+	body, err := encode(message, t.dkim)
+	if err != nil {
+		return err
+	}
+
+	// Only a permanent reply to the envelope or the data refuses the message itself; auth
+	// failures and dropped connections can clear up, so they stay retryable
+	err = t.pool.Send(message.From.Address, message.Recipients(), body)
+	var envelope *envelopeError
+	var reply *textproto.Error
+	if errors.As(err, &envelope) && errors.As(envelope.err, &reply) && reply.Code >= 500 && !isAuthReply(reply.Code) {
+		return fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+	return err
+}
+
+// isAuthReply reports the RFC 4954 codes, which relays also send in reply to MAIL FROM when the
+// session is not authenticated
+func isAuthReply(code int) bool {
+	return code == 530 || code == 534 || code == 535 || code == 538
+}
+
+// encode produces the wire form of a message, signed when a DKIM signer is configured
+func encode(message *Message, dkim *DKIMSigner) ([]byte, error) {
+	body, err := message.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+	if dkim != nil {
+		if body, err = dkim.Sign(body); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrRejected, err)
+		}
+	}
+	return body, nil
+}
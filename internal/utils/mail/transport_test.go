@@ -0,0 +1,66 @@
+package mail
+
+import (
+	"errors"
+	netmail "net/mail"
+	"testing"
+)
+
+func TestSMTPTransportRejections(t *testing.T) {
+	tests := []struct {
+		name     string
+		auth     bool
+		replies  map[string]string
+		rejected bool
+	}{
+		{"mail from refused", false, map[string]string{"MAIL": "553 5.7.1 sender not allowed"}, true},
+		{"recipient unknown", false, map[string]string{"RCPT": "550 5.1.1 no such user"}, true},
+		{"data refused", false, map[string]string{"DATA": "554 5.3.4 message too big"}, true},
+		{"message refused", false, map[string]string{".": "554 5.7.1 spam"}, true},
+		{"mailbox busy", false, map[string]string{"RCPT": "450 4.2.0 mailbox busy"}, false},
+		{"bad credentials", true, map[string]string{"AUTH": "535 5.7.8 authentication failed"}, false},
+		{"auth required", false, map[string]string{"MAIL": "530 5.7.0 authentication required"}, false},
+		{"service unavailable", false, map[string]string{"EHLO": "554 no service", "HELO": "554 no service"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestSMTPServer(t, tt.auth, tt.replies)
+			mechanism := AuthNone
+			if tt.auth {
+				mechanism = AuthPlain
+			}
+			transport := NewSMTPTransport(server.pool(t, mechanism), nil)
+
+			err := transport.Send(&Message{
+				From:    netmail.Address{Address: "noreply@example.com"},
+				To:      []netmail.Address{{Address: "jane@example.org"}},
+				Subject: "Hello",
+				Text:    "Hello Jane",
+			})
+			if err == nil {
+				t.Fatal("send succeeded")
+			}
+			if errors.Is(err, ErrRejected) != tt.rejected {
+				t.Errorf("errors.Is(%v, ErrRejected) = %v, want %v", err, !tt.rejected, tt.rejected)
+			}
+		})
+	}
+}
+
+func TestSMTPTransportConnectionError(t *testing.T) {
+	server := newTestSMTPServer(t, false, nil)
+	pool := server.pool(t, AuthNone)
+	server.listener.Close()
+
+	err := NewSMTPTransport(pool, nil).Send(&Message{
+		From: netmail.Address{Address: "noreply@example.com"},
+		To:   []netmail.Address{{Address: "jane@example.org"}},
+		Text: "Hello Jane",
+	})
+	if err == nil {
+		t.Fatal("send succeeded without a server")
+	}
+	if errors.Is(err, ErrRejected) {
+		t.Errorf("connection error %v is marked as rejected", err)
+	}
+}
@@ -2,6 +2,7 @@ package nats
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/nats-io/nats.go"
 	"log"
 	"notification-service/internal/services"
@@ -19,7 +20,6 @@ func mustMarshal(v interface{}) []byte {
 type Service interface {
 	Publish(subject string, data interface{}) error
 	Subscribe()
-	RetryPending(subject string)
 }
 
 type natsService struct {
@@ -52,12 +52,13 @@ func (s *natsService) Publish(subject string, data interface{}) error {
 }
 
 func (s *natsService) Subscribe() {
-	subjects := []string{"authentication", "forgot_password", "asset"}
+	subjects := []string{"authentication", "forgot_password", "email", "sms", "webhook", "chat", "asset"}
 
 	for _, subject := range subjects {
 		sub := subject
 		_, err := s.nc.Subscribe(sub, func(m *nats.Msg) {
-			log.Printf("Received message on %s: %s", sub, string(m.Data))
+			// Bodies carry tokens and personal data, so only their size is logged
+			log.Printf("Received message on %s (%d bytes)", sub, len(m.Data))
 			switch sub {
 			case "authentication":
 				if err := s.notificationService.SendNotificationAuthentication(m.Data); err != nil {
@@ -65,25 +66,43 @@ func (s *natsService) Subscribe() {
 				} else {
 					log.Printf("Processed 'authentication' successfully")
 				}
-			case "forgot_password":
-				// Handle 'forgot_password' message
+			case "forgot_password", "email":
+				// Handle email messages; the template is chosen from the event
 				if err := s.notificationService.SendNotificationEmail(m.Data); err != nil {
-					log.Printf("Error processing 'authentication': %v", err)
+					log.Printf("Error processing '%s': %v", sub, err)
 				} else {
-					log.Printf("Processed 'authentication' successfully")
+					log.Printf("Processed '%s' successfully", sub)
+				}
+			case "sms":
+				if err := s.notificationService.SendNotificationSMS(m.Data); err != nil {
+					log.Printf("Error processing 'sms': %v", err)
+				} else {
+					log.Printf("Processed 'sms' successfully")
+				}
+			case "webhook":
+				if err := s.notificationService.SendNotificationWebhook(m.Data); err != nil {
+					log.Printf("Error processing 'webhook': %v", err)
+				} else {
+					log.Printf("Processed 'webhook' successfully")
+				}
+			case "chat":
+				if err := s.notificationService.SendNotificationChat(m.Data); err != nil {
+					log.Printf("Error processing 'chat': %v", err)
+				} else {
+					log.Printf("Processed 'chat' successfully")
 				}
 			case "asset":
-				// Handle 'asset' message
-				log.Println("Handling asset message")
+				// Asset events go to the webhook subscribers and chat channels whose filters match
+				if err := errors.Join(s.notificationService.SendNotificationWebhook(m.Data), s.notificationService.SendNotificationChat(m.Data)); err != nil {
+					log.Printf("Error processing 'asset': %v", err)
+				} else {
+					log.Printf("Processed 'asset' successfully")
+				}
 			}
 		})
 		if err != nil {
 			log.Fatalf("Failed to subscribe to %s: %v", sub, err)
 		}
 	}
-	select {} // Keep the subscriber running indefinitely
-}
-
-func (s *natsService) RetryPending(subject string) {
-
+	// Subscriptions are served on the connection's own goroutines, so there is no need to block here
 }
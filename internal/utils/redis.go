@@ -18,6 +18,9 @@ type RedisService interface {
 	DeleteData(key, clientID string) error
 	GetToken(clientID string) (string, error)
 	DeleteToken(clientID string) error
+	AcquireLock(key, owner string, ttl time.Duration) (bool, error)
+	NextFencingToken(key string) (int64, error)
+	GetLockOwner(key string) (string, error)
 }
 
 // redisService implements RedisService
@@ -87,6 +90,33 @@ func (r redisService) DeleteToken(clientID string) error {
 	return r.Client.Del(r.Ctx, generateRedisKey(clientID)).Err()
 }
 
+// AcquireLock takes a lease on key for ttl, returning false when another owner holds it
+func (r redisService) AcquireLock(key, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := r.Client.SetNX(r.Ctx, "lock:"+key, owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock: %v", err)
+	}
+	return acquired, nil
+}
+
+// NextFencingToken returns a monotonically increasing token for the given resource
+func (r redisService) NextFencingToken(key string) (int64, error) {
+	token, err := r.Client.Incr(r.Ctx, "fence:"+key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to issue fencing token: %v", err)
+	}
+	return token, nil
+}
+
+// GetLockOwner returns the current holder of a lock, or an empty string when it is free
+func (r redisService) GetLockOwner(key string) (string, error) {
+	owner, err := r.Client.Get(r.Ctx, "lock:"+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return owner, err
+}
+
 // GetUserRedis retrieves a user from Redis
 func GetUserRedis(redis RedisService, key, clientID string) (*models.Users, error) {
 	var user models.Users
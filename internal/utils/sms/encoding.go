@@ -0,0 +1,94 @@
+package sms
+
+import "unicode/utf16"
+
+type Encoding string
+
+const (
+	GSM7 Encoding = "GSM-7"
+	UCS2 Encoding = "UCS-2"
+)
+
+// Segment capacities; concatenated messages lose room to the user data header
+const (
+	gsm7Single = 160
+	gsm7Multi  = 153
+	ucs2Single = 70
+	ucs2Multi  = 67
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet, one septet per character
+var gsm7Basic = runeSet("@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà")
+
+// gsm7Extension characters are sent as an escape plus one septet
+var gsm7Extension = runeSet("\f^{}\\[~]|€")
+
+// Info describes how a body goes over the air
+type Info struct {
+	Encoding Encoding `json:"encoding"`
+	Units    int      `json:"units"` // septets for GSM-7, UTF-16 code units for UCS-2
+	Segments int      `json:"segments"`
+}
+
+// Analyze picks GSM-7 when every character is in the GSM alphabet and UCS-2 otherwise, and counts
+// the segments the way handsets split them: an escape sequence or a surrogate pair is never
+// broken across two segments
+func Analyze(body string) Info {
+	encoding, single, multi := GSM7, gsm7Single, gsm7Multi
+	sizes, ok := gsm7Sizes(body)
+	if !ok {
+		encoding, single, multi = UCS2, ucs2Single, ucs2Multi
+		sizes = sizes[:0]
+		for _, r := range body {
+			sizes = append(sizes, utf16.RuneLen(r))
+		}
+	}
+
+	info := Info{Encoding: encoding}
+	for _, size := range sizes {
+		info.Units += size
+	}
+	if info.Units == 0 {
+		return info
+	}
+	if info.Units <= single {
+		info.Segments = 1
+		return info
+	}
+
+	info.Segments = 1
+	used := 0
+	for _, size := range sizes {
+		if used+size > multi {
+			info.Segments++
+			used = 0
+		}
+		used += size
+	}
+	return info
+}
+
+// gsm7Sizes returns the septets of every character, or false when one is outside the GSM alphabet
+func gsm7Sizes(body string) ([]int, bool) {
+	sizes := make([]int, 0, len(body))
+	for _, r := range body {
+		switch {
+		case gsm7Basic[r]:
+			sizes = append(sizes, 1)
+		case gsm7Extension[r]:
+			sizes = append(sizes, 2)
+		default:
+			return sizes, false
+		}
+	}
+	return sizes, true
+}
+
+func runeSet(chars string) map[rune]bool {
+	set := make(map[rune]bool)
+	for _, r := range chars {
+		set[r] = true
+	}
+	return set
+}
@@ -0,0 +1,39 @@
+package sms
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want Info
+	}{
+		{"empty", "", Info{Encoding: GSM7}},
+		{"gsm7 single segment", strings.Repeat("a", 160), Info{GSM7, 160, 1}},
+		{"gsm7 two segments", strings.Repeat("a", 161), Info{GSM7, 161, 2}},
+		{"gsm7 two full segments", strings.Repeat("a", 306), Info{GSM7, 306, 2}},
+		{"gsm7 three segments", strings.Repeat("a", 307), Info{GSM7, 307, 3}},
+		{"extension characters count twice", strings.Repeat("€", 80), Info{GSM7, 160, 1}},
+		{"extension character over the single limit", strings.Repeat("a", 159) + "€", Info{GSM7, 161, 2}},
+		// The escape and its septet stay together, pushing the euro into the second segment
+		{"extension character not split", strings.Repeat("a", 152) + "€" + strings.Repeat("a", 152), Info{GSM7, 306, 3}},
+		{"ucs2 single segment", strings.Repeat("Ж", 70), Info{UCS2, 70, 1}},
+		{"ucs2 two segments", strings.Repeat("Ж", 71), Info{UCS2, 71, 2}},
+		{"ucs2 two full segments", strings.Repeat("Ж", 134), Info{UCS2, 134, 2}},
+		{"ucs2 three segments", strings.Repeat("Ж", 135), Info{UCS2, 135, 3}},
+		{"one character forces ucs2", "Halo Budi, kode Anda 1234 ş", Info{UCS2, 27, 1}},
+		{"surrogate pair counts twice", strings.Repeat("😀", 35), Info{UCS2, 70, 1}},
+		// Both halves of the pair stay together, pushing it into the second segment
+		{"surrogate pair not split", strings.Repeat("Ж", 66) + "😀" + strings.Repeat("Ж", 66), Info{UCS2, 134, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Analyze(tt.body); got != tt.want {
+				t.Errorf("Analyze = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Providers selectable through Config.Provider
+const (
+	ProviderTwilio  = "twilio"  // Twilio Programmable Messaging and compatible APIs
+	ProviderZenziva = "zenziva" // Zenziva, an Indonesian aggregator
+)
+
+// ErrRejected marks messages the provider refused outright, such as an invalid number, which
+// resending cannot fix
+var ErrRejected = errors.New("message rejected")
+
+type Message struct {
+	To   string // E.164, e.g. +6281234567890
+	Body string
+}
+
+// Result identifies the accepted message so delivery receipts can be matched to it
+type Result struct {
+	MessageID string
+	Status    string
+}
+
+type Provider interface {
+	Send(message *Message) (*Result, error)
+}
+
+type Config struct {
+	Provider       string
+	Endpoint       string // defaults to the provider's public API; point it at a stub when testing
+	Username       string // Twilio account SID, Zenziva userkey
+	Password       string // Twilio auth token, Zenziva passkey
+	From           string // sender number or alphanumeric sender ID
+	StatusCallback string // URL the provider posts delivery receipts to, where supported
+	Timeout        time.Duration
+}
+
+func NewProvider(cfg Config) (Provider, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	switch cfg.Provider {
+	case ProviderTwilio:
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = defaultTwilioEndpoint
+		}
+		return &twilioProvider{cfg: cfg, client: client}, nil
+	case ProviderZenziva:
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = defaultZenzivaEndpoint
+		}
+		return &zenzivaProvider{cfg: cfg, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sms provider: %s", cfg.Provider)
+	}
+}
+
+// responseError describes a failed API call; malformed requests are rejections, while auth,
+// rate limit and server errors can clear up and stay retryable
+func responseError(provider string, resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	err := fmt.Errorf("%s responded %s: %s", provider, resp.Status, strings.TrimSpace(string(detail)))
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return fmt.Errorf("%w: %w", ErrRejected, err)
+	default:
+		return err
+	}
+}
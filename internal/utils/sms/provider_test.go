@@ -0,0 +1,136 @@
+package sms
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// testProviderServer answers every request with status and body, handing the request and its
+// parsed form to check
+func testProviderServer(t *testing.T, status int, body string, check func(r *http.Request, form url.Values)) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		if check != nil {
+			check(r, r.PostForm)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestTwilioSend(t *testing.T) {
+	endpoint := testProviderServer(t, http.StatusCreated, `{"sid":"SM123","status":"queued"}`, func(r *http.Request, form url.Values) {
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if user, password, _ := r.BasicAuth(); user != "AC123" || password != "token" {
+			t.Errorf("basic auth = %q:%q", user, password)
+		}
+		if form.Get("To") != "+6281234567890" || form.Get("From") != "+15005550006" || form.Get("Body") != "Kode Anda 1234" ||
+			form.Get("StatusCallback") != "https://example.com/sms/receipts/twilio" {
+			t.Errorf("form = %v", form)
+		}
+	})
+
+	provider, err := NewProvider(Config{
+		Provider:       ProviderTwilio,
+		Endpoint:       endpoint,
+		Username:       "AC123",
+		Password:       "token",
+		From:           "+15005550006",
+		StatusCallback: "https://example.com/sms/receipts/twilio",
+	})
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	result, err := provider.Send(&Message{To: "+6281234567890", Body: "Kode Anda 1234"})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if result.MessageID != "SM123" || result.Status != "queued" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestZenzivaSend(t *testing.T) {
+	endpoint := testProviderServer(t, http.StatusOK, `{"messageId":"987","to":"6281234567890","status":"1","text":"Success"}`, func(r *http.Request, form url.Values) {
+		if form.Get("userkey") != "user" || form.Get("passkey") != "pass" || form.Get("to") != "6281234567890" ||
+			form.Get("message") != "Kode Anda 1234" {
+			t.Errorf("form = %v", form)
+		}
+	})
+
+	provider, err := NewProvider(Config{Provider: ProviderZenziva, Endpoint: endpoint, Username: "user", Password: "pass"})
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	result, err := provider.Send(&Message{To: "+6281234567890", Body: "Kode Anda 1234"})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if result.MessageID != "987" || result.Status != "Success" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestZenzivaRefusalIsRetryable(t *testing.T) {
+	endpoint := testProviderServer(t, http.StatusOK, `{"messageId":"","to":"6281234567890","status":"0","text":"Saldo tidak cukup"}`, nil)
+	provider, err := NewProvider(Config{Provider: ProviderZenziva, Endpoint: endpoint})
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+
+	_, err = provider.Send(&Message{To: "+6281234567890", Body: "Kode Anda 1234"})
+	if err == nil {
+		t.Fatal("status 0 was treated as sent")
+	}
+	if errors.Is(err, ErrRejected) {
+		t.Errorf("status 0 error %v is marked as rejected", err)
+	}
+}
+
+func TestProviderStatus(t *testing.T) {
+	tests := []struct {
+		status   int
+		rejected bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusUnprocessableEntity, true},
+		{http.StatusUnauthorized, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+	}
+	for _, name := range []string{ProviderTwilio, ProviderZenziva} {
+		for _, tt := range tests {
+			t.Run(name+"/"+http.StatusText(tt.status), func(t *testing.T) {
+				endpoint := testProviderServer(t, tt.status, `{"message":"test"}`, nil)
+				provider, err := NewProvider(Config{Provider: name, Endpoint: endpoint})
+				if err != nil {
+					t.Fatalf("new provider: %v", err)
+				}
+				_, err = provider.Send(&Message{To: "+6281234567890", Body: "Kode Anda 1234"})
+				if err == nil {
+					t.Fatal("send succeeded")
+				}
+				if errors.Is(err, ErrRejected) != tt.rejected {
+					t.Errorf("errors.Is(%v, ErrRejected) = %v, want %v", err, !tt.rejected, tt.rejected)
+				}
+			})
+		}
+	}
+}
+
+func TestNewProviderUnknown(t *testing.T) {
+	if _, err := NewProvider(Config{Provider: "nexmo"}); err == nil {
+		t.Error("unknown provider was accepted")
+	}
+}
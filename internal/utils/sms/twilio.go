@@ -0,0 +1,56 @@
+package sms
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultTwilioEndpoint = "https://api.twilio.com"
+
+type twilioProvider struct {
+	cfg    Config
+	client *http.Client
+}
+
+type twilioMessage struct {
+	SID    string `json:"sid"`
+	Status string `json:"status"`
+}
+
+// Send creates a message through POST /2010-04-01/Accounts/{sid}/Messages.json
+func (p *twilioProvider) Send(message *Message) (*Result, error) {
+	form := url.Values{}
+	form.Set("To", message.To)
+	form.Set("From", p.cfg.From)
+	form.Set("Body", message.Body)
+	if p.cfg.StatusCallback != "" {
+		form.Set("StatusCallback", p.cfg.StatusCallback)
+	}
+
+	endpoint := strings.TrimRight(p.cfg.Endpoint, "/") + "/2010-04-01/Accounts/" + url.PathEscape(p.cfg.Username) + "/Messages.json"
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.cfg.Username, p.cfg.Password)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("twilio request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, responseError(ProviderTwilio, resp)
+	}
+
+	var created twilioMessage
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("decode twilio response: %w", err)
+	}
+	return &Result{MessageID: created.SID, Status: created.Status}, nil
+}
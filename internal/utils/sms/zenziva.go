@@ -0,0 +1,60 @@
+package sms
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultZenzivaEndpoint = "https://console.zenziva.net/reguler/api/sendsms/"
+
+type zenzivaProvider struct {
+	cfg    Config
+	client *http.Client
+}
+
+// zenzivaResponse reports success as status "1"; text carries the reason otherwise
+type zenzivaResponse struct {
+	MessageID string `json:"messageId"`
+	To        string `json:"to"`
+	Status    string `json:"status"`
+	Text      string `json:"text"`
+}
+
+// Send posts the message form; Zenziva takes local numbers without the plus sign. The sender ID
+// is bound to the account, so Config.From is not sent.
+func (p *zenzivaProvider) Send(message *Message) (*Result, error) {
+	form := url.Values{}
+	form.Set("userkey", p.cfg.Username)
+	form.Set("passkey", p.cfg.Password)
+	form.Set("to", strings.TrimPrefix(message.To, "+"))
+	form.Set("message", message.Body)
+
+	req, err := http.NewRequest(http.MethodPost, p.cfg.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("zenziva request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, responseError(ProviderZenziva, resp)
+	}
+
+	var sent zenzivaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sent); err != nil {
+		return nil, fmt.Errorf("decode zenziva response: %w", err)
+	}
+	// Failures such as an exhausted balance come back as 200 with status "0" and can clear up
+	if sent.Status != "1" {
+		return nil, fmt.Errorf("zenziva refused message: %s", sent.Text)
+	}
+	return &Result{MessageID: sent.MessageID, Status: sent.Text}, nil
+}
@@ -0,0 +1,5 @@
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS image_url  TEXT,
+    ADD COLUMN IF NOT EXISTS channel_id TEXT, -- Android notification channel
+    ADD COLUMN IF NOT EXISTS actions    TEXT, -- raw JSON array of {id, title, icon}
+    ADD COLUMN IF NOT EXISTS deep_link  TEXT; -- raw JSON {route, params}
@@ -0,0 +1,31 @@
+CREATE TABLE templates
+(
+    id          SERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    channel     TEXT NOT NULL,               -- 'email' or 'push'
+    description TEXT,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_templates_channel_name ON templates (channel, name);
+
+CREATE TABLE template_versions
+(
+    id           SERIAL PRIMARY KEY,
+    template_id  INTEGER NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+    version      INTEGER NOT NULL,
+    status       TEXT    NOT NULL DEFAULT 'draft', -- 'draft', 'published' or 'archived'
+    subject      TEXT,
+    html         TEXT,
+    text         TEXT,
+    title        TEXT,
+    body         TEXT,
+    created_by   TEXT,
+    published_at TIMESTAMP,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_template_versions_template_version ON template_versions (template_id, version);
+CREATE INDEX idx_template_versions_status ON template_versions (status);
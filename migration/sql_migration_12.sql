@@ -0,0 +1,5 @@
+ALTER TABLE templates
+    ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en'; -- e.g. 'id-ID', 'id', 'en'
+
+DROP INDEX IF EXISTS idx_templates_channel_name;
+CREATE UNIQUE INDEX idx_templates_channel_name_locale ON templates (channel, name, locale);
@@ -0,0 +1,8 @@
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS channel       TEXT NOT NULL DEFAULT 'push', -- 'push' or 'email'
+    ADD COLUMN IF NOT EXISTS recipient     TEXT,                         -- email address for the email channel
+    ADD COLUMN IF NOT EXISTS subject       TEXT,
+    ADD COLUMN IF NOT EXISTS template_name TEXT;
+
+CREATE INDEX idx_notifications_channel ON notifications (channel);
+CREATE INDEX idx_notifications_recipient ON notifications (recipient);
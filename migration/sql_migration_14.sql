@@ -0,0 +1,28 @@
+CREATE TABLE email_feedbacks
+(
+    id              SERIAL PRIMARY KEY,
+    recipient       TEXT NOT NULL,
+    type            TEXT NOT NULL, -- 'bounce' or 'complaint'
+    bounce_type     TEXT,          -- 'hard' or 'soft'
+    status          TEXT,          -- enhanced status code, e.g. '5.1.1'
+    reason          TEXT,
+    source          TEXT NOT NULL, -- 'sendgrid', 'mailgun', 'json' or 'dsn'
+    notification_id INTEGER,
+    occurred_at     TIMESTAMP,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_email_feedbacks_recipient ON email_feedbacks (recipient);
+CREATE INDEX idx_email_feedbacks_type ON email_feedbacks (type);
+CREATE INDEX idx_email_feedbacks_notification_id ON email_feedbacks (notification_id);
+
+CREATE TABLE email_suppressions
+(
+    id         SERIAL PRIMARY KEY,
+    email      TEXT NOT NULL,
+    reason     TEXT NOT NULL, -- 'hard_bounce', 'complaint' or 'manual'
+    detail     TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_email_suppressions_email ON email_suppressions (email);
@@ -0,0 +1,13 @@
+CREATE TABLE email_tracking_events
+(
+    id              SERIAL PRIMARY KEY,
+    notification_id INTEGER NOT NULL,
+    type            TEXT    NOT NULL, -- 'open' or 'click'
+    url             TEXT,             -- click target
+    ip              TEXT,
+    user_agent      TEXT,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_email_tracking_events_notification_id ON email_tracking_events (notification_id);
+CREATE INDEX idx_email_tracking_events_type ON email_tracking_events (type);
@@ -0,0 +1,5 @@
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS external_id  TEXT; -- provider message ID that delivery receipts refer to
+
+CREATE INDEX IF NOT EXISTS idx_notifications_external_id ON notifications (external_id);
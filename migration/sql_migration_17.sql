@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id         SERIAL PRIMARY KEY,
+    name       TEXT    NOT NULL,
+    url        TEXT    NOT NULL,
+    secret     TEXT    NOT NULL,
+    events     TEXT,                   -- JSON array of event type patterns, e.g. ["asset_*"]
+    active     BOOLEAN DEFAULT TRUE,
+    created_by TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_active ON webhook_subscriptions (active);
+
+CREATE TABLE IF NOT EXISTS webhook_attempts
+(
+    id              SERIAL PRIMARY KEY,
+    notification_id INTEGER NOT NULL,
+    subscription_id INTEGER NOT NULL,
+    attempt         INTEGER NOT NULL,
+    status_code     INTEGER,          -- NULL or 0 when no response arrived
+    latency_ms      BIGINT,
+    response        TEXT,
+    error           TEXT,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_notification_id ON webhook_attempts (notification_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_subscription_id ON webhook_attempts (subscription_id);
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS subscription_id INTEGER; -- webhook subscription the row is delivered to
+
+CREATE INDEX IF NOT EXISTS idx_notifications_subscription_id ON notifications (subscription_id);
@@ -0,0 +1,21 @@
+CREATE TABLE chat_channels
+(
+    id          SERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    platform    TEXT NOT NULL,          -- 'slack', 'telegram' or 'discord'
+    webhook_url TEXT,                   -- Slack and Discord
+    chat_id     TEXT,                   -- Telegram
+    events      TEXT,                   -- JSON array of event type patterns, e.g. ["asset_*"]
+    active      BOOLEAN DEFAULT TRUE,
+    created_by  TEXT,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_chat_channels_platform ON chat_channels (platform);
+CREATE INDEX idx_chat_channels_active ON chat_channels (active);
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS chat_channel_id INTEGER; -- chat channel the row is delivered to
+
+CREATE INDEX IF NOT EXISTS idx_notifications_chat_channel_id ON notifications (chat_channel_id);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS user_preferences
+(
+    user_id    INTEGER PRIMARY KEY,
+    locale     TEXT,      -- last locale the user registered a device with
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,19 @@
+ALTER TABLE cron_jobs
+    ADD COLUMN IF NOT EXISTS fencing_token BIGINT DEFAULT 0;
+
+CREATE TABLE cron_job_runs
+(
+    id            SERIAL PRIMARY KEY,
+    cron_job_id   INTEGER      NOT NULL,
+    job_name      VARCHAR(100) NOT NULL,
+    scheduled_at  TIMESTAMP    NOT NULL,
+    instance      VARCHAR(255) NOT NULL,        -- replica that attempted the run
+    fencing_token BIGINT    DEFAULT 0,
+    status        VARCHAR(20)  NOT NULL,        -- 'running', 'success', 'failed', 'skipped'
+    error         TEXT,
+    started_at    TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at   TIMESTAMP
+);
+
+CREATE INDEX idx_cron_job_runs_cron_job_id ON cron_job_runs (cron_job_id);
+CREATE INDEX idx_cron_job_runs_status ON cron_job_runs (status);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS email_attachment_contents
+(
+    id              SERIAL PRIMARY KEY,
+    notification_id INTEGER NOT NULL,
+    position        INTEGER NOT NULL, -- index into the stored email's attachments
+    content         TEXT,             -- base64, removed once the email is sent or failed
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_attachment_contents_notification_id ON email_attachment_contents (notification_id);
@@ -0,0 +1,2 @@
+ALTER TABLE cron_jobs
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64); -- IANA name, e.g. 'Asia/Jakarta'; NULL uses server time
@@ -0,0 +1,22 @@
+CREATE TABLE devices
+(
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER NOT NULL,
+    token        TEXT    NOT NULL,
+    platform     TEXT    NOT NULL,               -- 'android', 'ios' or 'web'
+    app_version  TEXT,
+    locale       TEXT,
+    is_active    BOOLEAN   DEFAULT TRUE,
+    last_seen_at TIMESTAMP,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_devices_token ON devices (token);
+CREATE INDEX idx_devices_user_id ON devices (user_id);
+CREATE INDEX idx_devices_is_active ON devices (is_active);
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS user_id INTEGER; -- set when the producer targeted a user instead of a token
+
+CREATE INDEX idx_notifications_user_id ON notifications (user_id);
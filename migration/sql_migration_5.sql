@@ -0,0 +1,3 @@
+ALTER TABLE devices
+    ADD COLUMN IF NOT EXISTS invalidated_at TIMESTAMP, -- set when FCM reports the token undeliverable
+    ADD COLUMN IF NOT EXISTS invalid_reason TEXT;
@@ -0,0 +1,16 @@
+CREATE TABLE device_topics
+(
+    id         SERIAL PRIMARY KEY,
+    device_id  INTEGER NOT NULL,
+    topic      TEXT    NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_device_topics_device_topic ON device_topics (device_id, topic);
+CREATE INDEX idx_device_topics_topic ON device_topics (topic);
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS topic     TEXT, -- set instead of target_token for topic sends
+    ADD COLUMN IF NOT EXISTS condition TEXT; -- e.g. 'assets' in topics && 'admins' in topics
+
+CREATE INDEX idx_notifications_topic ON notifications (topic);
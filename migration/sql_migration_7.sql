@@ -0,0 +1,5 @@
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS badge           INTEGER,               -- APNs badge count
+    ADD COLUMN IF NOT EXISTS category        TEXT,                  -- APNs category
+    ADD COLUMN IF NOT EXISTS thread_id       TEXT,                  -- APNs thread for grouping
+    ADD COLUMN IF NOT EXISTS mutable_content BOOLEAN DEFAULT FALSE; -- lets the iOS service extension modify the push
@@ -0,0 +1,7 @@
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS collapse_key  TEXT,      -- newer pushes with the same key replace older ones
+    ADD COLUMN IF NOT EXISTS expires_at    TIMESTAMP, -- no delivery or retry after this
+    ADD COLUMN IF NOT EXISTS next_retry_at TIMESTAMP;
+
+CREATE INDEX idx_notifications_expires_at ON notifications (expires_at);
+CREATE INDEX idx_notifications_next_retry_at ON notifications (next_retry_at);
@@ -0,0 +1,2 @@
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS silent BOOLEAN DEFAULT FALSE; -- data-only push that never shows a banner